@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"time"
+	"tools-backend/config"
+	"tools-backend/database"
+	"tools-backend/database/migrations"
+	"tools-backend/utils"
+)
+
+// Converts stored data to the current format (similar to Laravel's php artisan migrate)
+//
+//	go run ./cmd/migrate -timezone Europe/Paris -duration 1h -dry-run
+func main() {
+	config.LoadEnv()
+
+	timezone := flag.String("timezone", config.GetDefaultTimezone(), "IANA timezone the old event dates and times were written in")
+	duration := flag.Duration("duration", time.Hour, "length given to converted events")
+	dryRun := flag.Bool("dry-run", false, "report what would change without writing")
+	flag.Parse()
+
+	loc, err := utils.LoadTimezone(*timezone)
+	if err != nil {
+		log.Fatalf("Invalid timezone %q", *timezone)
+	}
+	if *duration <= 0 || *duration > config.GetEventMaxDuration() {
+		log.Fatalf("Invalid duration %s", *duration)
+	}
+
+	database.Connect()
+	defer database.Disconnect()
+
+	result, err := migrations.ConvertEventTimes(context.Background(), migrations.EventTimesOptions{
+		Timezone:        loc,
+		DefaultDuration: *duration,
+		DryRun:          *dryRun,
+	})
+	for _, warning := range result.Warnings {
+		log.Println(warning)
+	}
+	if err != nil {
+		log.Fatal("Event time migration failed:", err)
+	}
+
+	log.Printf("Event times: %d converted, %d skipped (dry run: %t)", result.Converted, result.Skipped, *dryRun)
+}
@@ -3,6 +3,9 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -36,3 +39,184 @@ func GetDatabaseName() string {
 func GetJWTSecret() string {
 	return GetEnv("JWT_SECRET", "your-secret-key")
 }
+
+// GetAccessTokenTTL returns how long an access token stays valid
+func GetAccessTokenTTL() time.Duration {
+	return getDuration("ACCESS_TOKEN_TTL", 15*time.Minute)
+}
+
+// GetRefreshTokenTTL returns how long a refresh token stays valid
+func GetRefreshTokenTTL() time.Duration {
+	return getDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour)
+}
+
+// getDuration reads a duration (e.g. "15m", "720h") from the environment with a default value
+func getDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		log.Printf("Invalid duration for %s, using default %s", key, defaultValue)
+		return defaultValue
+	}
+	return duration
+}
+
+// GetAppURL returns the public base URL used to build links in emails
+func GetAppURL() string {
+	return strings.TrimRight(GetEnv("APP_URL", "http://localhost:8080"), "/")
+}
+
+// GetMailDriver returns the mail driver: log, file or smtp
+func GetMailDriver() string {
+	return GetEnv("MAIL_DRIVER", "log")
+}
+
+// GetMailFrom returns the sender address for outgoing emails
+func GetMailFrom() string {
+	return GetEnv("MAIL_FROM", "no-reply@localhost")
+}
+
+// GetPasswordResetTTL returns how long a password reset token stays valid
+func GetPasswordResetTTL() time.Duration {
+	return getDuration("PASSWORD_RESET_TTL", time.Hour)
+}
+
+// GetEmailVerificationPolicy returns what unverified accounts may do: off, block or read_only
+func GetEmailVerificationPolicy() string {
+	switch policy := GetEnv("EMAIL_VERIFICATION_POLICY", "off"); policy {
+	case "block", "read_only":
+		return policy
+	default:
+		return "off"
+	}
+}
+
+// GetEmailVerificationTTL returns how long an email verification link stays valid
+func GetEmailVerificationTTL() time.Duration {
+	return getDuration("EMAIL_VERIFICATION_TTL", 48*time.Hour)
+}
+
+// GetAppName returns the application name (used as the TOTP issuer)
+func GetAppName() string {
+	return GetEnv("APP_NAME", "Tools Backend")
+}
+
+// GetTwoFactorChallengeTTL returns how long a user has to enter the 2FA code after the password
+func GetTwoFactorChallengeTTL() time.Duration {
+	return getDuration("TWO_FACTOR_CHALLENGE_TTL", 5*time.Minute)
+}
+
+// GetJWTKeysDir returns the directory holding the RS256/EdDSA key set (empty means HS256 with JWT_SECRET)
+func GetJWTKeysDir() string {
+	return GetEnv("JWT_KEYS_DIR", "")
+}
+
+// GetJWTIssuer returns the "iss" claim put into and required from every token
+func GetJWTIssuer() string {
+	return GetEnv("JWT_ISSUER", GetAppURL())
+}
+
+// GetLoginMaxAccountFailures returns the failed logins per account before it is locked
+func GetLoginMaxAccountFailures() int {
+	return getInt("LOGIN_MAX_ACCOUNT_FAILURES", 5)
+}
+
+// GetLoginMaxIPFailures returns the failed logins per IP address before it is locked
+func GetLoginMaxIPFailures() int {
+	return getInt("LOGIN_MAX_IP_FAILURES", 20)
+}
+
+// GetLoginFailureWindow returns how long failed logins are remembered
+func GetLoginFailureWindow() time.Duration {
+	return getDuration("LOGIN_FAILURE_WINDOW", 15*time.Minute)
+}
+
+// GetLoginLockoutDuration returns how long an account or IP stays locked
+func GetLoginLockoutDuration() time.Duration {
+	return getDuration("LOGIN_LOCKOUT_DURATION", 30*time.Minute)
+}
+
+// GetLoginBackoffBase returns the delay after the first failure (it doubles after each failure)
+func GetLoginBackoffBase() time.Duration {
+	return getDuration("LOGIN_BACKOFF_BASE", time.Second)
+}
+
+// GetAdminEmails returns the addresses that are made platform admins at startup
+func GetAdminEmails() []string {
+	var emails []string
+	for _, email := range strings.Split(GetEnv("ADMIN_EMAILS", ""), ",") {
+		if email = strings.ToLower(strings.TrimSpace(email)); email != "" {
+			emails = append(emails, email)
+		}
+	}
+	return emails
+}
+
+// GetReauthMaxAge returns how recent the sign-in of an account without a password must be to
+// change its email or password or delete it
+func GetReauthMaxAge() time.Duration {
+	return getDuration("REAUTH_MAX_AGE", 10*time.Minute)
+}
+
+// GetImpersonationTTL returns how long an admin impersonation token stays valid
+func GetImpersonationTTL() time.Duration {
+	return getDuration("IMPERSONATION_TTL", 15*time.Minute)
+}
+
+// GetImpersonationAllowDelete reports whether impersonated requests may use destructive routes (off by default)
+func GetImpersonationAllowDelete() bool {
+	allow, err := strconv.ParseBool(GetEnv("IMPERSONATION_ALLOW_DELETE", "false"))
+	return err == nil && allow
+}
+
+// GetEventMaxDuration returns how long a single event (or occurrence) may last
+func GetEventMaxDuration() time.Duration {
+	return getDuration("EVENT_MAX_DURATION", 30*24*time.Hour)
+}
+
+// GetEventInvitationTTL returns how long the link emailed to an invited guest stays valid
+func GetEventInvitationTTL() time.Duration {
+	return getDuration("EVENT_INVITATION_TTL", 30*24*time.Hour)
+}
+
+// GetEventRestoreDays returns how many days a deleted event can be restored before it is purged
+func GetEventRestoreDays() int {
+	return getInt("EVENT_RESTORE_DAYS", 30)
+}
+
+// GetEventPurgeInterval returns how often deleted events past their restore window are purged
+func GetEventPurgeInterval() time.Duration {
+	return getDuration("EVENT_PURGE_INTERVAL", time.Hour)
+}
+
+// GetEventRequireIfMatch reports whether changing an event requires an If-Match header (on by
+// default; turned off, the header is only checked when sent)
+func GetEventRequireIfMatch() bool {
+	require, err := strconv.ParseBool(GetEnv("EVENT_REQUIRE_IF_MATCH", "true"))
+	return err != nil || require
+}
+
+// GetDefaultTimezone returns the IANA timezone used when no other timezone is known
+// (viewers without a timezone, events converted by the migration)
+func GetDefaultTimezone() string {
+	return GetEnv("DEFAULT_TIMEZONE", "UTC")
+}
+
+// getInt reads a positive integer from the environment with a default value
+func getInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil || number <= 0 {
+		log.Printf("Invalid number for %s, using default %d", key, defaultValue)
+		return defaultValue
+	}
+	return number
+}
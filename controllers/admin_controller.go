@@ -0,0 +1,699 @@
+package controllers
+
+import (
+	"context"
+	"io"
+	"log"
+	"regexp"
+	"time"
+	"tools-backend/config"
+	"tools-backend/database"
+	"tools-backend/models"
+	"tools-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// adminListLimit caps how many documents the admin list endpoints return
+const adminListLimit = 100
+
+type AdminController struct{}
+
+// ListUsers returns a page of users, filtered by ?q= (name or email), ?role=, ?status=active|suspended and ?verified=true|false
+func (ac *AdminController) ListUsers(c *gin.Context) {
+	filter := bson.M{}
+
+	if query := c.Query("q"); query != "" {
+		regexPattern := bson.M{"$regex": regexp.QuoteMeta(query), "$options": "i"}
+		filter["$or"] = bson.A{
+			bson.M{"name": regexPattern},
+			bson.M{"email": regexPattern},
+		}
+	}
+
+	if role := models.PlatformRole(c.Query("role")); role != "" {
+		if !role.IsValid() {
+			utils.ErrorResponse(c, 400, "Invalid role. Must be: user, moderator, or admin")
+			return
+		}
+		if role == models.PlatformRoleUser {
+			// Accounts created before roles existed have no role field
+			filter["role"] = bson.M{"$in": bson.A{role, nil}}
+		} else {
+			filter["role"] = role
+		}
+	}
+
+	switch c.Query("status") {
+	case "":
+	case "active":
+		filter["suspended_at"] = nil
+	case "suspended":
+		filter["suspended_at"] = bson.M{"$ne": nil}
+	default:
+		utils.ErrorResponse(c, 400, "Invalid status. Must be: active or suspended")
+		return
+	}
+
+	switch c.Query("verified") {
+	case "":
+	case "true":
+		filter["verified_at"] = bson.M{"$ne": nil}
+	case "false":
+		filter["verified_at"] = nil
+	default:
+		utils.ErrorResponse(c, 400, "Invalid verified filter. Must be: true or false")
+		return
+	}
+
+	page, limit := parsePagination(c, 20, adminListLimit)
+	collection := database.GetCollection("users")
+
+	total, err := collection.CountDocuments(context.TODO(), filter)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to count users")
+		return
+	}
+
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip((page - 1) * limit).
+		SetLimit(limit)
+
+	cursor, err := collection.Find(context.TODO(), filter, findOptions)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to fetch users")
+		return
+	}
+	defer cursor.Close(context.TODO())
+
+	var users []models.User
+	if err = cursor.All(context.TODO(), &users); err != nil {
+		utils.ErrorResponse(c, 500, "Failed to process users")
+		return
+	}
+
+	userResponses := make([]models.UserResponse, 0, len(users))
+	for _, user := range users {
+		userResponses = append(userResponses, user.ToResponse())
+	}
+
+	utils.SuccessResponse(c, 200, "Users retrieved successfully", gin.H{
+		"users":      userResponses,
+		"pagination": paginationMeta(page, limit, total),
+	})
+}
+
+// GetUser returns a single user
+func (ac *AdminController) GetUser(c *gin.Context) {
+	user, ok := loadUserParam(c)
+	if !ok {
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "User retrieved successfully", user.ToResponse())
+}
+
+// UpdateUserRole changes a user's platform role
+func (ac *AdminController) UpdateUserRole(c *gin.Context) {
+	var req models.UpdateUserRoleRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, "Invalid request data")
+		return
+	}
+
+	// Validate request
+	if errors := utils.ValidateStruct(req); len(errors) > 0 {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	user, ok := loadUserParam(c)
+	if !ok {
+		return
+	}
+
+	// Admins cannot demote themselves, so there is always someone left to manage roles
+	if user.ID == actor.UserID {
+		utils.ErrorResponse(c, 400, "You cannot change your own role")
+		return
+	}
+
+	previousRole := user.EffectiveRole()
+	now := time.Now()
+	_, err := database.GetCollection("users").UpdateOne(
+		context.TODO(),
+		bson.M{"_id": user.ID},
+		bson.M{"$set": bson.M{"role": req.Role, "updated_at": now}},
+	)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to update role")
+		return
+	}
+
+	recordAdminAudit(c, models.AuditRoleChanged, actor.UserID, user.ID, map[string]interface{}{"from": previousRole, "to": req.Role})
+
+	user.Role = req.Role
+	user.UpdatedAt = now
+	utils.SuccessResponse(c, 200, "Role updated successfully", user.ToResponse())
+}
+
+// SuspendUser suspends an account and logs it out everywhere
+func (ac *AdminController) SuspendUser(c *gin.Context) {
+	var req models.SuspendUserRequest
+
+	// The reason is optional, so an empty body is fine
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		utils.ErrorResponse(c, 400, "Invalid request data")
+		return
+	}
+
+	// Validate request
+	if errors := utils.ValidateStruct(req); len(errors) > 0 {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	user, ok := loadUserParam(c)
+	if !ok {
+		return
+	}
+
+	if user.ID == actor.UserID {
+		utils.ErrorResponse(c, 400, "You cannot suspend your own account")
+		return
+	}
+
+	if user.SuspendedAt != nil {
+		utils.ErrorResponse(c, 400, "Account is already suspended")
+		return
+	}
+
+	now := time.Now()
+	update := bson.M{"suspended_at": now, "updated_at": now}
+	if req.Reason != "" {
+		update["suspended_reason"] = req.Reason
+	}
+
+	_, err := database.GetCollection("users").UpdateOne(context.TODO(), bson.M{"_id": user.ID}, bson.M{"$set": update})
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to suspend account")
+		return
+	}
+
+	// The middleware already rejects the account; revoking also stops refresh tokens
+	if err := revokeAllUserTokens(user.ID); err != nil {
+		utils.ErrorResponse(c, 500, "Failed to revoke sessions")
+		return
+	}
+
+	recordAdminAudit(c, models.AuditUserSuspended, actor.UserID, user.ID, map[string]interface{}{"reason": req.Reason})
+
+	user.SuspendedAt = &now
+	user.SuspendedReason = req.Reason
+	user.UpdatedAt = now
+	utils.SuccessResponse(c, 200, "Account suspended successfully", user.ToResponse())
+}
+
+// ReactivateUser lifts a suspension
+func (ac *AdminController) ReactivateUser(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	user, ok := loadUserParam(c)
+	if !ok {
+		return
+	}
+
+	if user.SuspendedAt == nil {
+		utils.ErrorResponse(c, 400, "Account is not suspended")
+		return
+	}
+
+	now := time.Now()
+	_, err := database.GetCollection("users").UpdateOne(
+		context.TODO(),
+		bson.M{"_id": user.ID},
+		bson.M{
+			"$set":   bson.M{"updated_at": now},
+			"$unset": bson.M{"suspended_at": "", "suspended_reason": ""},
+		},
+	)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to reactivate account")
+		return
+	}
+
+	recordAdminAudit(c, models.AuditUserReactivated, actor.UserID, user.ID, nil)
+
+	user.SuspendedAt = nil
+	user.SuspendedReason = ""
+	user.UpdatedAt = now
+	utils.SuccessResponse(c, 200, "Account reactivated successfully", user.ToResponse())
+}
+
+// ForcePasswordReset logs the user out everywhere and requires a new password before the next password login
+func (ac *AdminController) ForcePasswordReset(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	user, ok := loadUserParam(c)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	_, err := database.GetCollection("users").UpdateOne(
+		context.TODO(),
+		bson.M{"_id": user.ID},
+		bson.M{"$set": bson.M{"password_reset_required": true, "updated_at": now}},
+	)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to update account")
+		return
+	}
+
+	if err := revokeAllUserTokens(user.ID); err != nil {
+		utils.ErrorResponse(c, 500, "Failed to revoke sessions")
+		return
+	}
+
+	if err := sendPasswordResetEmail(user); err != nil {
+		utils.ErrorResponse(c, 500, "Failed to send password reset email")
+		return
+	}
+
+	recordAdminAudit(c, models.AuditPasswordForced, actor.UserID, user.ID, nil)
+
+	user.PasswordResetRequired = true
+	user.UpdatedAt = now
+	utils.SuccessResponse(c, 200, "Password reset required, a reset link has been sent to the user", user.ToResponse())
+}
+
+// ImpersonateUser issues a short-lived access token that lets an admin see the API as the user.
+// The token names the admin in its "act" claim, cannot be refreshed and every request made with it is audited.
+func (ac *AdminController) ImpersonateUser(c *gin.Context) {
+	var req models.ImpersonateUserRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, "Invalid request data")
+		return
+	}
+
+	// Validate request
+	if errors := utils.ValidateStruct(req); len(errors) > 0 {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	user, ok := loadUserParam(c)
+	if !ok {
+		return
+	}
+
+	if user.ID == actor.UserID {
+		utils.ErrorResponse(c, 400, "You cannot impersonate yourself")
+		return
+	}
+	if user.EffectiveRole() == models.PlatformRoleAdmin {
+		utils.ErrorResponse(c, 403, "Admins cannot be impersonated")
+		return
+	}
+	if user.SuspendedAt != nil {
+		utils.ErrorResponse(c, 400, "Suspended accounts cannot be impersonated")
+		return
+	}
+
+	ttl := config.GetImpersonationTTL()
+	accessToken, err := utils.GenerateAccessTokenWithTTL(user.ID.Hex(), user.Email, jwt.MapClaims{
+		"email_verified": user.VerifiedAt != nil,
+		"act": jwt.MapClaims{
+			"sub":   actor.UserID.Hex(),
+			"email": c.GetString("user_email"),
+		},
+	}, ttl)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to generate token")
+		return
+	}
+
+	recordAdminAudit(c, models.AuditImpersonationStarted, actor.UserID, user.ID, map[string]interface{}{
+		"reason":     req.Reason,
+		"jti":        accessToken.JTI,
+		"expires_at": accessToken.ExpiresAt,
+	})
+
+	utils.SuccessResponse(c, 200, "Impersonation token issued", gin.H{
+		"token":         accessToken.Token,
+		"token_type":    "Bearer",
+		"expires_in":    int(ttl.Seconds()),
+		"impersonating": user.ToResponse(),
+	})
+}
+
+// MergeUsers moves everything of a duplicate (source) account to the surviving user in the URL,
+// then deletes the duplicate. Every step can run again, and the source is only deleted once all of
+// them succeeded, so a merge that fails partway leaves the source in place to be merged again.
+func (ac *AdminController) MergeUsers(c *gin.Context) {
+	var req models.MergeUsersRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, "Invalid request data")
+		return
+	}
+
+	// Validate request
+	if errors := utils.ValidateStruct(req); len(errors) > 0 {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	target, ok := loadUserParam(c)
+	if !ok {
+		return
+	}
+
+	if req.SourceUserID == target.ID {
+		utils.ErrorResponse(c, 400, "Cannot merge an account into itself")
+		return
+	}
+	if req.SourceUserID == actor.UserID {
+		utils.ErrorResponse(c, 400, "You cannot merge away your own account")
+		return
+	}
+
+	usersCollection := database.GetCollection("users")
+	var source models.User
+	err := usersCollection.FindOne(context.TODO(), bson.M{"_id": req.SourceUserID}).Decode(&source)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			utils.ErrorResponse(c, 404, "Source user not found")
+		} else {
+			utils.ErrorResponse(c, 500, "Database error")
+		}
+		return
+	}
+
+	movedEvents, err := mergeEventParticipants(source.ID, target.ID)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to move event participation")
+		return
+	}
+
+	movedStatuses, err := mergeEventStatuses(source.ID, target.ID)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to move event statuses")
+		return
+	}
+
+	invitations, err := database.GetCollection("event_invitations").UpdateMany(
+		context.TODO(),
+		bson.M{"invited_by": source.ID},
+		bson.M{"$set": bson.M{"invited_by": target.ID}},
+	)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to move invitations")
+		return
+	}
+
+	// The surviving account keeps the more privileged role, the verified state and the linked
+	// identities of both ($addToSet, so that merging again does not link them twice)
+	update := bson.M{"updated_at": time.Now()}
+	if source.EffectiveRole().Outranks(target.EffectiveRole()) {
+		update["role"] = source.EffectiveRole()
+		target.Role = source.EffectiveRole()
+	}
+	verifiedNow := target.VerifiedAt == nil && source.VerifiedAt != nil
+	if verifiedNow {
+		update["verified_at"] = *source.VerifiedAt
+		target.VerifiedAt = source.VerifiedAt
+	}
+	userUpdate := bson.M{"$set": update}
+	if len(source.Identities) > 0 {
+		userUpdate["$addToSet"] = bson.M{"identities": bson.M{"$each": source.Identities}}
+	}
+	if _, err := usersCollection.UpdateOne(context.TODO(), bson.M{"_id": target.ID}, userUpdate); err != nil {
+		utils.ErrorResponse(c, 500, "Failed to update surviving account")
+		return
+	}
+
+	// Invitations are claimed when an address is verified
+	if verifiedNow {
+		claimInvitations(c, target)
+	}
+
+	// Remove the duplicate and everything that would let it sign in
+	if err := revokeAllUserTokens(source.ID); err != nil {
+		utils.ErrorResponse(c, 500, "Failed to revoke sessions")
+		return
+	}
+	for _, name := range []string{"api_keys", "sessions", "refresh_tokens", "password_resets"} {
+		if _, err := database.GetCollection(name).DeleteMany(context.TODO(), bson.M{"user_id": source.ID}); err != nil {
+			utils.ErrorResponse(c, 500, "Failed to delete account data")
+			return
+		}
+	}
+	if _, err := usersCollection.DeleteOne(context.TODO(), bson.M{"_id": source.ID}); err != nil {
+		utils.ErrorResponse(c, 500, "Failed to delete merged account")
+		return
+	}
+
+	recordAdminAudit(c, models.AuditUsersMerged, actor.UserID, target.ID, map[string]interface{}{
+		"source_user_id": source.ID,
+		"source_email":   source.Email,
+		"events":         movedEvents,
+		"statuses":       movedStatuses,
+		"invitations":    invitations.ModifiedCount,
+	})
+
+	utils.SuccessResponse(c, 200, "Accounts merged successfully", gin.H{
+		"user":              target.ToResponse(),
+		"moved_events":      movedEvents,
+		"moved_statuses":    movedStatuses,
+		"moved_invitations": invitations.ModifiedCount,
+	})
+}
+
+// mergeEventParticipants replaces the source user with the target in every event; when both
+// take part in the same event the target keeps the more privileged event role (and the ownership)
+func mergeEventParticipants(sourceID, targetID primitive.ObjectID) (int, error) {
+	collection := database.GetCollection("events")
+
+	cursor, err := collection.Find(context.TODO(), bson.M{"participants.user_id": sourceID})
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(context.TODO())
+
+	var events []models.Event
+	if err = cursor.All(context.TODO(), &events); err != nil {
+		return 0, err
+	}
+
+	for _, event := range events {
+		var sourceRole models.EventRole
+		targetIndex := -1
+		participants := make([]models.EventParticipant, 0, len(event.Participants))
+		for _, p := range event.Participants {
+			if p.UserID == sourceID {
+				sourceRole = p.Role
+				continue
+			}
+			if p.UserID == targetID {
+				targetIndex = len(participants)
+			}
+			participants = append(participants, p)
+		}
+
+		if targetIndex == -1 {
+			participants = append(participants, models.EventParticipant{UserID: targetID, Role: sourceRole})
+		} else if sourceRole == models.RoleOrganizer {
+			participants[targetIndex].Role = models.RoleOrganizer
+		}
+
+		set := bson.M{"participants": participants, "updated_at": time.Now()}
+		if event.Owner() == sourceID {
+			set["owner_id"] = targetID
+		}
+
+		_, err := collection.UpdateOne(context.TODO(), bson.M{"_id": event.ID}, bumpVersion(bson.M{"$set": set}))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(events), nil
+}
+
+// mergeEventStatuses moves the source user's event statuses to the target; when both
+// answered for the same event the most recent answer wins (a seat held by the other is freed)
+func mergeEventStatuses(sourceID, targetID primitive.ObjectID) (int, error) {
+	collection := database.GetCollection("event_statuses")
+
+	cursor, err := collection.Find(context.TODO(), bson.M{"user_id": sourceID})
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(context.TODO())
+
+	var statuses []models.EventStatus
+	if err = cursor.All(context.TODO(), &statuses); err != nil {
+		return 0, err
+	}
+
+	// Seats of the answers already deleted are freed even when a later step fails
+	var freedSeats []models.EventStatus
+	defer func() { releaseSeats(freedSeats) }()
+	for _, status := range statuses {
+		var existing models.EventStatus
+		err := collection.FindOne(context.TODO(), bson.M{
+			"event_id":        status.EventID,
+			"user_id":         targetID,
+			"occurrence_date": occurrenceFilter(status.OccurrenceDate),
+		}).Decode(&existing)
+		if err != nil && err != mongo.ErrNoDocuments {
+			return 0, err
+		}
+
+		if err == nil {
+			// Keep only the newer of the two answers
+			obsolete := status
+			if status.UpdatedAt.After(existing.UpdatedAt) {
+				obsolete = existing
+			}
+			if _, err := collection.DeleteOne(context.TODO(), bson.M{"_id": obsolete.ID}); err != nil {
+				return 0, err
+			}
+			if obsolete.Status == models.StatusGoing {
+				freedSeats = append(freedSeats, obsolete)
+			}
+			if obsolete.ID == status.ID {
+				continue
+			}
+		}
+
+		if _, err := collection.UpdateOne(context.TODO(), bson.M{"_id": status.ID}, bson.M{"$set": bson.M{"user_id": targetID}}); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(statuses), nil
+}
+
+// ListEvents returns the most recently created events of all users (?deleted=true lists the
+// deleted events waiting to be purged instead)
+func (ac *AdminController) ListEvents(c *gin.Context) {
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetLimit(adminListLimit)
+
+	filter := notDeleted(bson.M{})
+	if c.Query("deleted") == "true" {
+		filter = bson.M{"deleted_at": bson.M{"$exists": true}}
+	}
+
+	cursor, err := database.GetCollection("events").Find(context.TODO(), filter, findOptions)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to fetch events")
+		return
+	}
+	defer cursor.Close(context.TODO())
+
+	var events []models.Event
+	if err = cursor.All(context.TODO(), &events); err != nil {
+		utils.ErrorResponse(c, 500, "Failed to process events")
+		return
+	}
+
+	eventResponses := make([]models.EventResponse, 0, len(events))
+	for _, event := range events {
+		eventResponses = append(eventResponses, event.ToResponse())
+	}
+
+	utils.SuccessResponse(c, 200, "Events retrieved successfully", localizeEvents(c, eventResponses))
+}
+
+// EnsureConfiguredAdmins gives the accounts listed in ADMIN_EMAILS the admin role (similar to a Laravel seeder)
+func EnsureConfiguredAdmins() {
+	emails := config.GetAdminEmails()
+	if len(emails) == 0 {
+		return
+	}
+
+	result, err := database.GetCollection("users").UpdateMany(
+		context.TODO(),
+		bson.M{"email": bson.M{"$in": emails}, "role": bson.M{"$ne": models.PlatformRoleAdmin}},
+		bson.M{"$set": bson.M{"role": models.PlatformRoleAdmin, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		log.Printf("Failed to promote configured admins: %v", err)
+		return
+	}
+	if result.ModifiedCount > 0 {
+		log.Printf("Promoted %d account(s) from ADMIN_EMAILS to admin", result.ModifiedCount)
+	}
+}
+
+// loadUserParam fetches the user named by the :id route parameter, writing an error response on failure
+func loadUserParam(c *gin.Context) (*models.User, bool) {
+	userID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, 400, "Invalid user ID")
+		return nil, false
+	}
+
+	var user models.User
+	err = database.GetCollection("users").FindOne(context.TODO(), bson.M{"_id": userID}).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			utils.ErrorResponse(c, 404, "User not found")
+		} else {
+			utils.ErrorResponse(c, 500, "Database error")
+		}
+		return nil, false
+	}
+
+	return &user, true
+}
+
+// recordAdminAudit writes an audit entry for an admin action on a user account
+func recordAdminAudit(c *gin.Context, action string, actorID, targetID primitive.ObjectID, details map[string]interface{}) {
+	utils.RecordAudit(models.AuditLog{
+		Action:       action,
+		ActorID:      &actorID,
+		TargetUserID: &targetID,
+		IP:           c.ClientIP(),
+		UserAgent:    c.Request.UserAgent(),
+		Details:      details,
+	})
+}
@@ -0,0 +1,171 @@
+package controllers
+
+import (
+	"context"
+	"time"
+	"tools-backend/database"
+	"tools-backend/models"
+	"tools-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// apiKeyPrefix marks our keys so they are easy to spot (e.g. by secret scanners)
+const apiKeyPrefix = "tbk_"
+
+type APIKeyController struct{}
+
+// CreateAPIKey creates a personal API key; the plain key is only returned once
+func (akc *APIKeyController) CreateAPIKey(c *gin.Context) {
+	var req models.CreateAPIKeyRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, "Invalid request data")
+		return
+	}
+
+	// Validate request
+	if errors := utils.ValidateStruct(req); len(errors) > 0 {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		utils.ErrorResponse(c, 401, "User ID not found in token")
+		return
+	}
+
+	userID, ok := userIDInterface.(string)
+	if !ok {
+		utils.ErrorResponse(c, 401, "Invalid user ID format")
+		return
+	}
+
+	userObjectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		utils.ErrorResponse(c, 400, "Invalid user ID")
+		return
+	}
+
+	secret, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to generate API key")
+		return
+	}
+	rawKey := apiKeyPrefix + secret
+
+	apiKey := models.APIKey{
+		UserID:    userObjectID,
+		Name:      req.Name,
+		Prefix:    rawKey[:len(apiKeyPrefix)+8],
+		KeyHash:   utils.HashToken(rawKey),
+		Scopes:    req.Scopes,
+		CreatedAt: time.Now(),
+	}
+	if req.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		apiKey.ExpiresAt = &expiresAt
+	}
+
+	result, err := database.GetCollection("api_keys").InsertOne(context.TODO(), apiKey)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to create API key")
+		return
+	}
+
+	apiKey.ID = result.InsertedID.(primitive.ObjectID)
+	utils.SuccessResponse(c, 201, "API key created. Copy it now, it will not be shown again", gin.H{
+		"key":     rawKey,
+		"api_key": apiKey.ToResponse(),
+	})
+}
+
+// ListAPIKeys returns the API keys of the current user
+func (akc *APIKeyController) ListAPIKeys(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		utils.ErrorResponse(c, 401, "User ID not found in token")
+		return
+	}
+
+	userID, ok := userIDInterface.(string)
+	if !ok {
+		utils.ErrorResponse(c, 401, "Invalid user ID format")
+		return
+	}
+
+	userObjectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		utils.ErrorResponse(c, 400, "Invalid user ID")
+		return
+	}
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cursor, err := database.GetCollection("api_keys").Find(context.TODO(), bson.M{"user_id": userObjectID}, findOptions)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to fetch API keys")
+		return
+	}
+	defer cursor.Close(context.TODO())
+
+	var apiKeys []models.APIKey
+	if err = cursor.All(context.TODO(), &apiKeys); err != nil {
+		utils.ErrorResponse(c, 500, "Failed to process API keys")
+		return
+	}
+
+	apiKeyResponses := make([]models.APIKeyResponse, 0, len(apiKeys))
+	for _, apiKey := range apiKeys {
+		apiKeyResponses = append(apiKeyResponses, apiKey.ToResponse())
+	}
+
+	utils.SuccessResponse(c, 200, "API keys retrieved successfully", apiKeyResponses)
+}
+
+// RevokeAPIKey revokes one of the current user's API keys
+func (akc *APIKeyController) RevokeAPIKey(c *gin.Context) {
+	apiKeyObjectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, 400, "Invalid API key ID")
+		return
+	}
+
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		utils.ErrorResponse(c, 401, "User ID not found in token")
+		return
+	}
+
+	userID, ok := userIDInterface.(string)
+	if !ok {
+		utils.ErrorResponse(c, 401, "Invalid user ID format")
+		return
+	}
+
+	userObjectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		utils.ErrorResponse(c, 400, "Invalid user ID")
+		return
+	}
+
+	result, err := database.GetCollection("api_keys").UpdateOne(
+		context.TODO(),
+		bson.M{"_id": apiKeyObjectID, "user_id": userObjectID, "revoked_at": nil},
+		bson.M{"$set": bson.M{"revoked_at": time.Now()}},
+	)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to revoke API key")
+		return
+	}
+
+	if result.MatchedCount == 0 {
+		utils.ErrorResponse(c, 404, "API key not found")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "API key revoked successfully", nil)
+}
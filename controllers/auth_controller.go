@@ -2,18 +2,22 @@ package controllers
 
 import (
 	"context"
-	// "log"
+	"fmt"
+	"log"
+	"net/url"
 	"time"
+	"tools-backend/config"
 	"tools-backend/database"
+	"tools-backend/mailer"
 	"tools-backend/models"
 	"tools-backend/utils"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"golang.org/x/crypto/bcrypt"
-	// "io"
 )
 
 type AuthController struct{}
@@ -66,7 +70,11 @@ func (ac *AuthController) Register(c *gin.Context) {
 	}
 
 	user.ID = result.InsertedID.(primitive.ObjectID)
-	utils.SuccessResponse(c, 201, "User created successfully", user.ToResponse())
+
+	// Registration succeeds even if the email cannot be sent; the user can ask for a new one
+	sendVerificationEmail(&user)
+
+	utils.SuccessResponse(c, 201, "User created successfully. Please check your email to verify your address", user.ToResponse())
 }
 
 // Login handles user login (similar to Laravel's login method)
@@ -87,12 +95,18 @@ func (ac *AuthController) Login(c *gin.Context) {
 		return
 	}
 
+	// Refuse early while the account or IP is locked or backing off
+	if !checkLoginThrottle(c, loginData.Email) {
+		return
+	}
+
 	// Find user
 	collection := database.GetCollection("users")
 	var user models.User
 	err := collection.FindOne(context.TODO(), bson.M{"email": loginData.Email}).Decode(&user)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
+			recordLoginFailure(c, loginData.Email)
 			utils.ErrorResponse(c, 401, "Invalid credentials")
 		} else {
 			utils.ErrorResponse(c, 500, "Database error")
@@ -103,19 +117,667 @@ func (ac *AuthController) Login(c *gin.Context) {
 	// Check password
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(loginData.Password))
 	if err != nil {
+		recordLoginFailure(c, loginData.Email)
 		utils.ErrorResponse(c, 401, "Invalid credentials")
 		return
 	}
 
-	// Generate JWT token
-	token, err := utils.GenerateJWT(user.ID.Hex(), user.Email)
+	// An admin forced a reset (e.g. the password leaked), so the old password is no longer enough
+	if user.PasswordResetRequired {
+		utils.ErrorResponse(c, 403, "A password reset is required. Please use the link sent to your email or request a new one")
+		return
+	}
+
+	// Unverified accounts cannot log in when the policy is "block"
+	if user.VerifiedAt == nil && config.GetEmailVerificationPolicy() == "block" {
+		utils.ErrorResponse(c, 403, "Please verify your email address before logging in")
+		return
+	}
+
+	completeLogin(c, &user)
+}
+
+// LoginTwoFactor completes a login by checking the 2FA code against the challenge token
+func (ac *AuthController) LoginTwoFactor(c *gin.Context) {
+	var req models.TwoFactorLoginRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, "Invalid request data")
+		return
+	}
+
+	// Validate request
+	if errors := utils.ValidateStruct(req); len(errors) > 0 {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	if req.Code == "" && req.RecoveryCode == "" {
+		utils.ErrorResponse(c, 400, "Either code or recovery_code is required")
+		return
+	}
+
+	claims, err := utils.ParseSignedToken(req.ChallengeToken, "2fa_challenge")
+	if err != nil {
+		utils.ErrorResponse(c, 401, "Invalid or expired challenge token")
+		return
+	}
+
+	userID, _ := claims["user_id"].(string)
+	userObjectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		utils.ErrorResponse(c, 401, "Invalid or expired challenge token")
+		return
+	}
+
+	var user models.User
+	err = database.GetCollection("users").FindOne(context.TODO(), bson.M{"_id": userObjectID}).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			utils.ErrorResponse(c, 401, "Invalid or expired challenge token")
+		} else {
+			utils.ErrorResponse(c, 500, "Database error")
+		}
+		return
+	}
+
+	if !user.TwoFactorEnabled {
+		utils.ErrorResponse(c, 401, "Invalid or expired challenge token")
+		return
+	}
+
+	// Code guessing counts towards the same limits as password guessing
+	if !checkLoginThrottle(c, user.Email) {
+		return
+	}
+
+	valid, err := verifyTwoFactor(&user, req.Code, req.RecoveryCode)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to verify two-factor code")
+		return
+	}
+	if !valid {
+		recordLoginFailure(c, user.Email)
+		utils.ErrorResponse(c, 401, "Invalid two-factor code")
+		return
+	}
+
+	issueLoginTokens(c, &user)
+}
+
+// RefreshToken exchanges a refresh token for a new token pair (the old refresh token is rotated)
+func (ac *AuthController) RefreshToken(c *gin.Context) {
+	var req models.RefreshTokenRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, "Invalid request data")
+		return
+	}
+
+	// Validate request
+	if errors := utils.ValidateStruct(req); len(errors) > 0 {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	collection := database.GetCollection("refresh_tokens")
+	var refreshToken models.RefreshToken
+
+	err := collection.FindOne(context.TODO(), bson.M{"token_hash": utils.HashToken(req.RefreshToken)}).Decode(&refreshToken)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			utils.ErrorResponse(c, 401, "Invalid refresh token")
+		} else {
+			utils.ErrorResponse(c, 500, "Database error")
+		}
+		return
+	}
+
+	// A rotated token being presented again means it leaked: kill the whole family
+	if refreshToken.RevokedAt != nil {
+		revokeTokenFamily(refreshToken.FamilyID)
+		utils.ErrorResponse(c, 401, "Refresh token reuse detected, please log in again")
+		return
+	}
+
+	if refreshToken.ExpiresAt.Before(time.Now()) {
+		utils.ErrorResponse(c, 401, "Refresh token has expired")
+		return
+	}
+
+	// The session may have been revoked from another device
+	revokedSessions, err := database.GetCollection("sessions").CountDocuments(context.TODO(), bson.M{
+		"_id":        refreshToken.FamilyID,
+		"revoked_at": bson.M{"$ne": nil},
+	})
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Database error")
+		return
+	}
+	if revokedSessions > 0 {
+		utils.ErrorResponse(c, 401, "Session has been revoked, please log in again")
+		return
+	}
+
+	// Mark the token as used; losing this race also counts as reuse
+	result, err := collection.UpdateOne(
+		context.TODO(),
+		bson.M{"_id": refreshToken.ID, "revoked_at": nil},
+		bson.M{"$set": bson.M{"revoked_at": time.Now()}},
+	)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to rotate refresh token")
+		return
+	}
+	if result.ModifiedCount == 0 {
+		revokeTokenFamily(refreshToken.FamilyID)
+		utils.ErrorResponse(c, 401, "Refresh token reuse detected, please log in again")
+		return
+	}
+
+	var user models.User
+	err = database.GetCollection("users").FindOne(context.TODO(), bson.M{"_id": refreshToken.UserID}).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			utils.ErrorResponse(c, 401, "Invalid refresh token")
+		} else {
+			utils.ErrorResponse(c, 500, "Database error")
+		}
+		return
+	}
+
+	if !checkNotSuspended(c, &user) {
+		return
+	}
+
+	tokens, newRefreshToken, err := issueTokenPair(&user, refreshToken.FamilyID)
 	if err != nil {
 		utils.ErrorResponse(c, 500, "Failed to generate token")
 		return
 	}
 
-	utils.SuccessResponse(c, 200, "Login successful", gin.H{
-		"user":  user.ToResponse(),
-		"token": token,
+	collection.UpdateOne(
+		context.TODO(),
+		bson.M{"_id": refreshToken.ID},
+		bson.M{"$set": bson.M{"replaced_by": newRefreshToken.ID}},
+	)
+
+	utils.SuccessResponse(c, 200, "Token refreshed successfully", tokens)
+}
+
+// Logout revokes the current access token and, if provided, the refresh token of this login
+func (ac *AuthController) Logout(c *gin.Context) {
+	var req models.LogoutRequest
+
+	// The body is optional
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.ErrorResponse(c, 400, "Invalid request data")
+			return
+		}
+	}
+
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		utils.ErrorResponse(c, 401, "User ID not found in token")
+		return
+	}
+
+	userID, ok := userIDInterface.(string)
+	if !ok {
+		utils.ErrorResponse(c, 401, "Invalid user ID format")
+		return
+	}
+
+	userObjectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		utils.ErrorResponse(c, 400, "Invalid user ID")
+		return
+	}
+
+	if err := denyAccessToken(c.GetString("token_jti"), userObjectID, c.GetTime("token_exp")); err != nil {
+		utils.ErrorResponse(c, 500, "Failed to revoke token")
+		return
+	}
+
+	// End the session this token belongs to
+	if sessionID, err := primitive.ObjectIDFromHex(c.GetString("session_id")); err == nil {
+		if err := revokeTokenFamily(sessionID); err != nil {
+			utils.ErrorResponse(c, 500, "Failed to revoke session")
+			return
+		}
+	}
+
+	if req.RefreshToken != "" {
+		var refreshToken models.RefreshToken
+		err := database.GetCollection("refresh_tokens").FindOne(context.TODO(), bson.M{
+			"token_hash": utils.HashToken(req.RefreshToken),
+			"user_id":    userObjectID,
+		}).Decode(&refreshToken)
+
+		if err == nil {
+			if err := revokeTokenFamily(refreshToken.FamilyID); err != nil {
+				utils.ErrorResponse(c, 500, "Failed to revoke refresh token")
+				return
+			}
+		} else if err != mongo.ErrNoDocuments {
+			utils.ErrorResponse(c, 500, "Database error")
+			return
+		}
+	}
+
+	utils.SuccessResponse(c, 200, "Logged out successfully", nil)
+}
+
+// LogoutAll revokes every refresh token and live access token of the current user
+func (ac *AuthController) LogoutAll(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		utils.ErrorResponse(c, 401, "User ID not found in token")
+		return
+	}
+
+	userID, ok := userIDInterface.(string)
+	if !ok {
+		utils.ErrorResponse(c, 401, "Invalid user ID format")
+		return
+	}
+
+	userObjectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		utils.ErrorResponse(c, 400, "Invalid user ID")
+		return
+	}
+
+	if err := denyAccessToken(c.GetString("token_jti"), userObjectID, c.GetTime("token_exp")); err != nil {
+		utils.ErrorResponse(c, 500, "Failed to revoke token")
+		return
+	}
+
+	if err := revokeAllUserTokens(userObjectID); err != nil {
+		utils.ErrorResponse(c, 500, "Failed to revoke tokens")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Logged out from all devices successfully", nil)
+}
+
+// ForgotPassword sends a password reset link (similar to Laravel's Password::sendResetLink())
+func (ac *AuthController) ForgotPassword(c *gin.Context) {
+	var req models.ForgotPasswordRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, "Invalid request data")
+		return
+	}
+
+	// Validate request
+	if errors := utils.ValidateStruct(req); len(errors) > 0 {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	// Always answer the same way so the endpoint cannot be used to discover accounts
+	const message = "If an account with that email exists, a password reset link has been sent"
+
+	var user models.User
+	err := database.GetCollection("users").FindOne(context.TODO(), bson.M{"email": req.Email}).Decode(&user)
+	if err != nil {
+		if err != mongo.ErrNoDocuments {
+			utils.ErrorResponse(c, 500, "Database error")
+			return
+		}
+		utils.SuccessResponse(c, 200, message, nil)
+		return
+	}
+
+	// A failure must not answer differently from an unknown address
+	if err := sendPasswordResetEmail(&user); err != nil {
+		log.Printf("Failed to start a password reset for %s: %v", user.Email, err)
+	}
+
+	utils.SuccessResponse(c, 200, message, nil)
+}
+
+// ResetPassword sets a new password using a reset token and logs the user out everywhere
+func (ac *AuthController) ResetPassword(c *gin.Context) {
+	var req models.ResetPasswordRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, "Invalid request data")
+		return
+	}
+
+	// Validate request
+	if errors := utils.ValidateStruct(req); len(errors) > 0 {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	// Consume the token atomically so it can only be used once
+	var reset models.PasswordReset
+	err := database.GetCollection("password_resets").FindOneAndUpdate(
+		context.TODO(),
+		bson.M{
+			"token_hash": utils.HashToken(req.Token),
+			"used_at":    nil,
+			"expires_at": bson.M{"$gt": time.Now()},
+		},
+		bson.M{"$set": bson.M{"used_at": time.Now()}},
+	).Decode(&reset)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			utils.ErrorResponse(c, 400, "Invalid or expired reset token")
+		} else {
+			utils.ErrorResponse(c, 500, "Database error")
+		}
+		return
+	}
+
+	// Hash password
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to hash password")
+		return
+	}
+
+	result, err := database.GetCollection("users").UpdateOne(
+		context.TODO(),
+		bson.M{"_id": reset.UserID},
+		bson.M{
+			"$set":   bson.M{"password": string(hashedPassword), "updated_at": time.Now()},
+			"$unset": bson.M{"password_reset_required": ""},
+		},
+	)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to update password")
+		return
+	}
+	if result.MatchedCount == 0 {
+		utils.ErrorResponse(c, 400, "Invalid or expired reset token")
+		return
+	}
+
+	// Invalidate every existing session of the user
+	if err := revokeAllUserTokens(reset.UserID); err != nil {
+		utils.ErrorResponse(c, 500, "Failed to revoke existing sessions")
+		return
+	}
+
+	// A new password also lifts a lockout
+	var user models.User
+	if err := database.GetCollection("users").FindOne(context.TODO(), bson.M{"_id": reset.UserID}).Decode(&user); err == nil {
+		clearAccountLock(user.Email)
+	}
+
+	utils.SuccessResponse(c, 200, "Password reset successfully", nil)
+}
+
+// sendPasswordResetEmail replaces any pending reset token of the user with a new one and emails it
+func sendPasswordResetEmail(user *models.User) error {
+	collection := database.GetCollection("password_resets")
+
+	rawToken, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return err
+	}
+
+	// Only the most recent link stays valid
+	if _, err := collection.DeleteMany(context.TODO(), bson.M{"user_id": user.ID, "used_at": nil}); err != nil {
+		return err
+	}
+
+	ttl := config.GetPasswordResetTTL()
+	reset := models.PasswordReset{
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(rawToken),
+		ExpiresAt: time.Now().Add(ttl),
+		CreatedAt: time.Now(),
+	}
+	if _, err := collection.InsertOne(context.TODO(), reset); err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", config.GetAppURL(), url.QueryEscape(rawToken))
+	err = mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nUse the link below to choose a new password. It expires in %s and can only be used once.\n\n%s\n\nIf you did not request this, you can ignore this email.",
+			user.Name, ttl, link),
+	})
+	if err != nil {
+		log.Printf("Failed to send password reset email to %s: %v", user.Email, err)
+	}
+	return err
+}
+
+// VerifyEmail confirms an email address using the signed token from the verification email
+func (ac *AuthController) VerifyEmail(c *gin.Context) {
+	tokenString := c.Query("token")
+	if tokenString == "" {
+		utils.ErrorResponse(c, 400, "Verification token is required")
+		return
+	}
+
+	claims, err := utils.ParseSignedToken(tokenString, "email_verify")
+	if err != nil {
+		utils.ErrorResponse(c, 400, "Invalid or expired verification token")
+		return
+	}
+
+	userID, _ := claims["user_id"].(string)
+	email, _ := claims["email"].(string)
+
+	userObjectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		utils.ErrorResponse(c, 400, "Invalid or expired verification token")
+		return
+	}
+
+	// The token is bound to the address it was sent to
+	collection := database.GetCollection("users")
+	var user models.User
+	err = collection.FindOne(context.TODO(), bson.M{"_id": userObjectID, "email": email}).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			utils.ErrorResponse(c, 400, "Invalid or expired verification token")
+		} else {
+			utils.ErrorResponse(c, 500, "Database error")
+		}
+		return
+	}
+
+	if user.VerifiedAt != nil {
+		utils.SuccessResponse(c, 200, "Email address already verified", user.ToResponse())
+		return
+	}
+
+	now := time.Now()
+	_, err = collection.UpdateOne(
+		context.TODO(),
+		bson.M{"_id": user.ID},
+		bson.M{"$set": bson.M{"verified_at": now, "updated_at": now}},
+	)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to verify email address")
+		return
+	}
+
+	user.VerifiedAt = &now
+	user.UpdatedAt = now
+
+	// Events the address was invited to as a guest belong to the account once it owns the address
+	claimInvitations(c, &user)
+
+	utils.SuccessResponse(c, 200, "Email address verified successfully", user.ToResponse())
+}
+
+// ResendVerification sends a new verification email to an unverified account
+func (ac *AuthController) ResendVerification(c *gin.Context) {
+	var req models.ResendVerificationRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, "Invalid request data")
+		return
+	}
+
+	// Validate request
+	if errors := utils.ValidateStruct(req); len(errors) > 0 {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	// Always answer the same way so the endpoint cannot be used to discover accounts
+	const message = "If an unverified account with that email exists, a verification link has been sent"
+
+	var user models.User
+	err := database.GetCollection("users").FindOne(context.TODO(), bson.M{"email": req.Email}).Decode(&user)
+	if err != nil {
+		if err != mongo.ErrNoDocuments {
+			utils.ErrorResponse(c, 500, "Database error")
+			return
+		}
+		utils.SuccessResponse(c, 200, message, nil)
+		return
+	}
+
+	// A failure must not answer differently from an unknown or verified address
+	if user.VerifiedAt == nil {
+		if err := sendVerificationEmail(&user); err != nil {
+			log.Printf("Failed to resend the verification email to %s: %v", user.Email, err)
+		}
+	}
+
+	utils.SuccessResponse(c, 200, message, nil)
+}
+
+// sendVerificationEmail emails a signed link that confirms the user's current address
+func sendVerificationEmail(user *models.User) error {
+	ttl := config.GetEmailVerificationTTL()
+	token, err := utils.GenerateSignedToken("email_verify", jwt.MapClaims{
+		"user_id": user.ID.Hex(),
+		"email":   user.Email,
+	}, ttl)
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/api/v1/email/verify?token=%s", config.GetAppURL(), url.QueryEscape(token))
+	err = mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm your email address by opening the link below. It expires in %s.\n\n%s",
+			user.Name, ttl, link),
+	})
+	if err != nil {
+		log.Printf("Failed to send verification email to %s: %v", user.Email, err)
+	}
+	return err
+}
+
+// UnlockAccount lifts a login lockout using the signed link from the lockout email
+func (ac *AuthController) UnlockAccount(c *gin.Context) {
+	tokenString := c.Query("token")
+	if tokenString == "" {
+		utils.ErrorResponse(c, 400, "Unlock token is required")
+		return
+	}
+
+	claims, err := utils.ParseSignedToken(tokenString, "account_unlock")
+	if err != nil {
+		utils.ErrorResponse(c, 400, "Invalid or expired unlock token")
+		return
+	}
+
+	email, _ := claims["email"].(string)
+	userObjectID, err := primitive.ObjectIDFromHex(fmt.Sprint(claims["user_id"]))
+	if err != nil || email == "" {
+		utils.ErrorResponse(c, 400, "Invalid or expired unlock token")
+		return
+	}
+
+	if err := clearAccountLock(email); err != nil {
+		utils.ErrorResponse(c, 500, "Failed to unlock account")
+		return
+	}
+
+	utils.RecordAudit(models.AuditLog{
+		Action:       models.AuditAccountUnlocked,
+		TargetUserID: &userObjectID,
+		IP:           c.ClientIP(),
+		UserAgent:    c.Request.UserAgent(),
+		Details:      map[string]interface{}{"email": email, "via": "email_link"},
 	})
+
+	utils.SuccessResponse(c, 200, "Account unlocked successfully. You can log in again", nil)
+}
+
+// JWKS publishes the public signing keys so other services can verify tokens
+func (ac *AuthController) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(200, utils.JWKS())
+}
+
+// completeLogin finishes a successful first factor: it returns a 2FA challenge when
+// two-factor authentication is enabled, otherwise it issues the token pair
+func completeLogin(c *gin.Context, user *models.User) {
+	if !checkNotSuspended(c, user) {
+		return
+	}
+
+	// With 2FA enabled the first factor only earns a short-lived challenge token
+	if user.TwoFactorEnabled {
+		challengeTTL := config.GetTwoFactorChallengeTTL()
+		challengeToken, err := utils.GenerateSignedToken("2fa_challenge", jwt.MapClaims{
+			"user_id": user.ID.Hex(),
+		}, challengeTTL)
+		if err != nil {
+			utils.ErrorResponse(c, 500, "Failed to generate token")
+			return
+		}
+
+		utils.SuccessResponse(c, 200, "Two-factor authentication required", gin.H{
+			"two_factor_required": true,
+			"challenge_token":     challengeToken,
+			"expires_in":          int(challengeTTL.Seconds()),
+		})
+		return
+	}
+
+	issueLoginTokens(c, user)
+}
+
+// issueLoginTokens starts a session for a fully authenticated user and returns the tokens
+func issueLoginTokens(c *gin.Context, user *models.User) {
+	if !checkNotSuspended(c, user) {
+		return
+	}
+
+	recordLoginSuccess(user.Email)
+
+	// A new login starts a new session (the refresh token family)
+	sessionID, err := startSession(c, user)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to create session")
+		return
+	}
+
+	// Generate access and refresh tokens
+	tokens, _, err := issueTokenPair(user, sessionID)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to generate token")
+		return
+	}
+
+	tokens["user"] = user.ToResponse()
+	utils.SuccessResponse(c, 200, "Login successful", tokens)
+}
+
+// checkNotSuspended refuses to log in suspended accounts, writing an error response
+func checkNotSuspended(c *gin.Context, user *models.User) bool {
+	if user.SuspendedAt != nil {
+		utils.ErrorResponse(c, 403, "Account has been suspended")
+		return false
+	}
+	return true
 }
@@ -0,0 +1,266 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+	"tools-backend/config"
+	"tools-backend/database"
+	"tools-backend/mailer"
+	"tools-backend/models"
+	"tools-backend/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// takeSeat reserves a seat for a "going" answer. The counter only increments while it is below
+// the capacity; when the event is full the upsert hits the unique index instead, so concurrent
+// answers can never overbook. Events without a capacity always have a seat.
+func takeSeat(event *models.Event, occurrenceDate string) (bool, error) {
+	if event.Capacity <= 0 {
+		return true, nil
+	}
+
+	// Two first answers can race to create the counter; the loser retries once against it
+	for attempt := 0; attempt < 2; attempt++ {
+		_, err := database.GetCollection("event_seats").UpdateOne(
+			context.TODO(),
+			bson.M{
+				"event_id":        event.ID,
+				"occurrence_date": occurrenceDate,
+				"taken":           bson.M{"$lt": event.Capacity},
+			},
+			bson.M{"$inc": bson.M{"taken": 1}},
+			options.Update().SetUpsert(true),
+		)
+		if !mongo.IsDuplicateKeyError(err) {
+			return err == nil, err
+		}
+	}
+	return false, nil
+}
+
+// releaseSeat gives back the seat of a "going" answer
+func releaseSeat(event *models.Event, occurrenceDate string) {
+	if event.Capacity <= 0 {
+		return
+	}
+
+	_, err := database.GetCollection("event_seats").UpdateOne(
+		context.TODO(),
+		bson.M{"event_id": event.ID, "occurrence_date": occurrenceDate, "taken": bson.M{"$gt": 0}},
+		bson.M{"$inc": bson.M{"taken": -1}},
+	)
+	if err != nil {
+		log.Printf("Failed to release a seat of event %s: %v", event.ID.Hex(), err)
+	}
+}
+
+// promoteWaitlisted gives free seats to the earliest waitlisted attendees and tells them by email
+func promoteWaitlisted(event *models.Event, occurrenceDate string) {
+	statuses := database.GetCollection("event_statuses")
+	for {
+		seated, err := takeSeat(event, occurrenceDate)
+		if err != nil || !seated {
+			return
+		}
+
+		var status models.EventStatus
+		err = statuses.FindOneAndUpdate(
+			context.TODO(),
+			bson.M{
+				"event_id":        event.ID,
+				"occurrence_date": occurrenceFilter(occurrenceDate),
+				"status":          models.StatusWaitlisted,
+			},
+			bson.M{
+				"$set":   bson.M{"status": models.StatusGoing, "updated_at": time.Now()},
+				"$unset": bson.M{"waitlisted_at": ""},
+			},
+			options.FindOneAndUpdate().
+				SetSort(bson.D{{Key: "waitlisted_at", Value: 1}, {Key: "_id", Value: 1}}).
+				SetReturnDocument(options.After),
+		).Decode(&status)
+		if err != nil {
+			// Nobody is waiting (or the lookup failed): keep the seat free
+			releaseSeat(event, occurrenceDate)
+			if err != mongo.ErrNoDocuments {
+				log.Printf("Failed to promote the waitlist of event %s: %v", event.ID.Hex(), err)
+			}
+			return
+		}
+
+		sendWaitlistPromotion(event, status)
+	}
+}
+
+// capacityChanged hands the seats freed by a new capacity (or by an event that stopped repeating,
+// whose answers for the whole event now hold the seats) to the waitlists. The counters are only
+// kept while there is a limit, so they are rebuilt first when the event had none.
+func capacityChanged(old, updated *models.Event) {
+	stoppedRepeating := old.Recurrence != nil && updated.Recurrence == nil
+	if updated.Capacity == old.Capacity && !stoppedRepeating {
+		return
+	}
+
+	if old.Capacity == 0 || stoppedRepeating {
+		if err := recountSeats(updated.ID); err != nil {
+			// Promoting without counters would overbook the event
+			log.Printf("Failed to recount the seats of event %s: %v", updated.ID.Hex(), err)
+			return
+		}
+	}
+	promoteAllWaitlists(updated)
+}
+
+// promoteAllWaitlists fills the free seats of every occurrence that has a waitlist (after the capacity changed)
+func promoteAllWaitlists(event *models.Event) {
+	occurrenceDates, err := database.GetCollection("event_statuses").Distinct(
+		context.TODO(),
+		"occurrence_date",
+		bson.M{"event_id": event.ID, "status": models.StatusWaitlisted},
+	)
+	if err != nil {
+		log.Printf("Failed to load the waitlists of event %s: %v", event.ID.Hex(), err)
+		return
+	}
+
+	for _, value := range occurrenceDates {
+		occurrenceDate, _ := value.(string)
+		promoteWaitlisted(event, occurrenceDate)
+	}
+}
+
+// recountSeats rebuilds the seat counters of an event from its "going" answers (guests included)
+// (when a capacity is set on an event that had none, the counters were not kept)
+func recountSeats(eventID primitive.ObjectID) error {
+	cursor, err := database.GetCollection("event_statuses").Aggregate(context.TODO(), mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"event_id": eventID, "status": models.StatusGoing}}},
+		{{Key: "$group", Value: bson.M{"_id": bson.M{"$ifNull": bson.A{"$occurrence_date", ""}}, "taken": bson.M{"$sum": 1}}}},
+	})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(context.TODO())
+
+	type seatCount struct {
+		OccurrenceDate string `bson:"_id"`
+		Taken          int    `bson:"taken"`
+	}
+	var counts []seatCount
+	if err = cursor.All(context.TODO(), &counts); err != nil {
+		return err
+	}
+
+	// Guests answer for the whole event
+	guests, err := database.GetCollection("event_invitations").CountDocuments(context.TODO(), bson.M{"event_id": eventID, "status": models.StatusGoing})
+	if err != nil {
+		return err
+	}
+	if guests > 0 {
+		counted := false
+		for i := range counts {
+			if counts[i].OccurrenceDate == "" {
+				counts[i].Taken += int(guests)
+				counted = true
+			}
+		}
+		if !counted {
+			counts = append(counts, seatCount{Taken: int(guests)})
+		}
+	}
+
+	seats := database.GetCollection("event_seats")
+	if _, err := seats.DeleteMany(context.TODO(), bson.M{"event_id": eventID}); err != nil {
+		return err
+	}
+	for _, count := range counts {
+		_, err := seats.InsertOne(context.TODO(), models.EventSeats{
+			EventID:        eventID,
+			OccurrenceDate: count.OccurrenceDate,
+			Taken:          count.Taken,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// releaseUserSeats frees the seats held by a user (account deleted, removed from events)
+// and promotes the waitlists; eventIDs limits it to some events (nil for all)
+func releaseUserSeats(userID primitive.ObjectID, eventIDs []primitive.ObjectID) {
+	filter := bson.M{"user_id": userID, "status": models.StatusGoing}
+	if eventIDs != nil {
+		filter["event_id"] = bson.M{"$in": eventIDs}
+	}
+
+	cursor, err := database.GetCollection("event_statuses").Find(context.TODO(), filter)
+	if err != nil {
+		log.Printf("Failed to load the seats of user %s: %v", userID.Hex(), err)
+		return
+	}
+	var statuses []models.EventStatus
+	err = cursor.All(context.TODO(), &statuses)
+	cursor.Close(context.TODO())
+	if err != nil || len(statuses) == 0 {
+		return
+	}
+
+	// The answers are deleted first so the promotion cannot pick them
+	database.GetCollection("event_statuses").DeleteMany(context.TODO(), filter)
+	releaseSeats(statuses)
+}
+
+// releaseSeats frees the seats of "going" answers that were removed and promotes the waitlists
+func releaseSeats(statuses []models.EventStatus) {
+	events := map[primitive.ObjectID]*models.Event{}
+	for _, status := range statuses {
+		event, loaded := events[status.EventID]
+		if !loaded {
+			var found models.Event
+			if err := database.GetCollection("events").FindOne(context.TODO(), bson.M{"_id": status.EventID}).Decode(&found); err == nil {
+				event = &found
+			}
+			events[status.EventID] = event
+		}
+		if event == nil {
+			continue
+		}
+
+		releaseSeat(event, status.OccurrenceDate)
+		promoteWaitlisted(event, status.OccurrenceDate)
+	}
+}
+
+// sendWaitlistPromotion tells an attendee they moved from the waitlist to "going"
+func sendWaitlistPromotion(event *models.Event, status models.EventStatus) {
+	var user models.User
+	if err := database.GetCollection("users").FindOne(context.TODO(), bson.M{"_id": status.UserID}).Decode(&user); err != nil {
+		log.Printf("Failed to load waitlisted user %s: %v", status.UserID.Hex(), err)
+		return
+	}
+
+	startAt := event.StartAt
+	if status.OccurrenceDate != "" {
+		startAt, _ = occurrenceTimes(event, status.OccurrenceDate)
+	}
+	loc := event.Zone()
+	if userLoc, err := utils.LoadTimezone(user.Timezone); err == nil {
+		loc = userLoc
+	}
+
+	err := mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "A seat opened up: " + event.Title,
+		Body: fmt.Sprintf("Hi %s,\n\nA seat became available for \"%s\" on %s and you have been moved from the waitlist to going.\n\nIf you can no longer attend, please update your answer so the seat goes to the next person:\n\n%s/api/v1/events/%s",
+			user.Name, event.Title, startAt.In(loc).Format("Monday 2 January 2006, 15:04 MST"), config.GetAppURL(), event.ID.Hex()),
+	})
+	if err != nil {
+		log.Printf("Failed to send waitlist email to %s: %v", user.Email, err)
+	}
+}
@@ -2,9 +2,12 @@ package controllers
 
 import (
 	"context"
+	"fmt"
 	"time"
+	"tools-backend/config"
 	"tools-backend/database"
 	"tools-backend/models"
+	"tools-backend/policies"
 	"tools-backend/utils"
 
 	"github.com/gin-gonic/gin"
@@ -30,20 +33,40 @@ func (ec *EventController) CreateEvent(c *gin.Context) {
 		return
 	}
 
-	// Validate date is not in the past
-	eventDate, err := time.Parse("2006-01-02", req.Date)
-	if err != nil {
-		utils.ErrorResponse(c, 400, "Invalid date format. Use YYYY-MM-DD")
+	// Validate timezone and duration; times are stored in UTC
+	startAt, endAt := req.StartAt.UTC(), req.EndAt.UTC()
+	if !checkEventTimes(c, startAt, endAt, req.Timezone) {
+		return
+	}
+	if startAt.Before(time.Now()) {
+		utils.ErrorResponse(c, 400, "Event cannot start in the past")
 		return
 	}
 
-	// Compare with today (start of day)
-	now := time.Now()
-	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	// Create event with creator as organizer
+	event := models.Event{
+		Title:       req.Title,
+		Description: req.Description,
+		StartAt:     startAt,
+		EndAt:       endAt,
+		Timezone:    req.Timezone,
+		Location:    req.Location,
+		Capacity:    req.Capacity,
+		Visibility:  req.Visibility,
+		Recurrence:  req.Recurrence,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	if event.Visibility == "" {
+		event.Visibility = models.VisibilityPrivate
+	}
 
-	if eventDate.Before(today) {
-		utils.ErrorResponse(c, 400, "Event date cannot be in the past")
-		return
+	// Validate the recurrence against the first (local) date
+	if req.Recurrence != nil {
+		if err := normalizeRecurrence(req.Recurrence, event.LocalDate()); err != nil {
+			utils.ErrorResponse(c, 400, "Invalid recurrence: "+err.Error())
+			return
+		}
 	}
 
 	// Get user ID from context (set by auth middleware)
@@ -66,22 +89,14 @@ func (ec *EventController) CreateEvent(c *gin.Context) {
 		return
 	}
 
-	// Create event with creator as organizer
-	event := models.Event{
-		Title:       req.Title,
-		Description: req.Description,
-		Date:        req.Date,
-		Time:        req.Time,
-		Location:    req.Location,
-		Participants: []models.EventParticipant{
-			{
-				UserID: userObjectID,
-				Role:   models.RoleOrganizer,
-			},
+	event.Participants = []models.EventParticipant{
+		{
+			UserID: userObjectID,
+			Role:   models.RoleOrganizer,
 		},
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
 	}
+	event.OwnerID = userObjectID
+	event.Version = 1
 
 	// Insert event
 	collection := database.GetCollection("events")
@@ -92,11 +107,19 @@ func (ec *EventController) CreateEvent(c *gin.Context) {
 	}
 
 	event.ID = result.InsertedID.(primitive.ObjectID)
-	utils.SuccessResponse(c, 201, "Event created successfully", event.ToResponse())
+	recordEventChange(c, models.HistoryCreated, nil, event.ID, nil)
+	c.Header("ETag", eventETag(&event))
+	utils.SuccessResponse(c, 201, "Event created successfully", localizeEvent(c, event.ToResponse()))
 }
 
 // GetOrganizedEvents returns all events organized by the user
+// (recurring events as one entry per occurrence between ?from= and ?to=)
 func (ec *EventController) GetOrganizedEvents(c *gin.Context) {
+	from, to, ok := occurrenceWindow(c)
+	if !ok {
+		return
+	}
+
 	userIDInterface, exists := c.Get("user_id")
 	if !exists {
 		utils.ErrorResponse(c, 401, "User ID not found in token")
@@ -127,7 +150,7 @@ func (ec *EventController) GetOrganizedEvents(c *gin.Context) {
 		},
 	}
 
-	cursor, err := collection.Find(context.TODO(), filter)
+	cursor, err := collection.Find(context.TODO(), notDeleted(filter))
 	if err != nil {
 		utils.ErrorResponse(c, 500, "Failed to fetch events")
 		return
@@ -140,16 +163,19 @@ func (ec *EventController) GetOrganizedEvents(c *gin.Context) {
 		return
 	}
 
-	eventResponses := make([]models.EventResponse, 0, len(events))
-	for _, event := range events {
-		eventResponses = append(eventResponses, event.ToResponse())
-	}
+	eventResponses := localizeEvents(c, expandEvents(events, from, to))
 
 	utils.SuccessResponse(c, 200, "Organized events retrieved successfully", eventResponses)
 }
 
 // GetInvitedEvents returns all events the user is invited to
+// (recurring events as one entry per occurrence between ?from= and ?to=)
 func (ec *EventController) GetInvitedEvents(c *gin.Context) {
+	from, to, ok := occurrenceWindow(c)
+	if !ok {
+		return
+	}
+
 	userIDInterface, exists := c.Get("user_id")
 	if !exists {
 		utils.ErrorResponse(c, 401, "User ID not found in token")
@@ -180,7 +206,7 @@ func (ec *EventController) GetInvitedEvents(c *gin.Context) {
 		},
 	}
 
-	cursor, err := collection.Find(context.TODO(), filter)
+	cursor, err := collection.Find(context.TODO(), notDeleted(filter))
 	if err != nil {
 		utils.ErrorResponse(c, 500, "Failed to fetch events")
 		return
@@ -210,26 +236,25 @@ func (ec *EventController) GetInvitedEvents(c *gin.Context) {
 		// Log error but continue, statuses will just be empty
 	}
 
-	statusMap := make(map[primitive.ObjectID]models.EventStatusValue)
+	statusMap := make(map[statusKey]models.EventStatus)
 	if err == nil {
 		var statuses []models.EventStatus
 		if err = statusCursor.All(context.TODO(), &statuses); err == nil {
 			for _, s := range statuses {
-				statusMap[s.EventID] = s.Status
+				statusMap[statusKey{s.EventID, s.OccurrenceDate}] = s
 			}
 		}
 		statusCursor.Close(context.TODO())
 	}
 
-	eventResponses := make([]models.EventResponse, 0, len(events))
-	for _, event := range events {
-		resp := event.ToResponse()
-		if status, exists := statusMap[event.ID]; exists {
-			resp.MyStatus = status
+	eventResponses := localizeEvents(c, expandEvents(events, from, to))
+	for i := range eventResponses {
+		resp := &eventResponses[i]
+		if status, exists := effectiveStatus(statusMap, resp.ID, resp.OccurrenceDate); exists {
+			resp.MyStatus = status.Status
 		} else {
 			resp.MyStatus = models.StatusNoResponse
 		}
-		eventResponses = append(eventResponses, resp)
 	}
 
 	utils.SuccessResponse(c, 200, "Invited events retrieved successfully", eventResponses)
@@ -245,10 +270,15 @@ func (ec *EventController) GetEventByID(c *gin.Context) {
 		return
 	}
 
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
 	collection := database.GetCollection("events")
 	var event models.Event
 
-	err = collection.FindOne(context.TODO(), bson.M{"_id": eventObjectID}).Decode(&event)
+	err = collection.FindOne(context.TODO(), notDeleted(bson.M{"_id": eventObjectID})).Decode(&event)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			utils.ErrorResponse(c, 404, "Event not found")
@@ -258,7 +288,20 @@ func (ec *EventController) GetEventByID(c *gin.Context) {
 		return
 	}
 
-	utils.SuccessResponse(c, 200, "Event retrieved successfully", event.ToResponse())
+	// Private events are hidden from everyone else (404, so their IDs cannot be probed)
+	if !policies.CanViewEvent(actor, &event) {
+		utils.ErrorResponse(c, 404, "Event not found")
+		return
+	}
+
+	// Clients sending the ETag they have get 304 while the event is unchanged
+	if notModified(c, &event) {
+		return
+	}
+
+	// Non-participants do not see who takes part in the event
+	responses := hideParticipants(c, []models.EventResponse{event.ToResponse()})
+	utils.SuccessResponse(c, 200, "Event retrieved successfully", localizeEvent(c, responses[0]))
 }
 
 // InviteToEvent invites users to an event (only organizer can invite)
@@ -283,21 +326,8 @@ func (ec *EventController) InviteToEvent(c *gin.Context) {
 		return
 	}
 
-	userIDInterface, exists := c.Get("user_id")
-	if !exists {
-		utils.ErrorResponse(c, 401, "User ID not found in token")
-		return
-	}
-
-	userID, ok := userIDInterface.(string)
+	actor, ok := currentActor(c)
 	if !ok {
-		utils.ErrorResponse(c, 401, "Invalid user ID format")
-		return
-	}
-
-	userObjectID, err := primitive.ObjectIDFromHex(userID)
-	if err != nil {
-		utils.ErrorResponse(c, 400, "Invalid user ID")
 		return
 	}
 
@@ -305,7 +335,7 @@ func (ec *EventController) InviteToEvent(c *gin.Context) {
 	var event models.Event
 
 	// Find event and check if user is organizer
-	err = collection.FindOne(context.TODO(), bson.M{"_id": eventObjectID}).Decode(&event)
+	err = collection.FindOne(context.TODO(), notDeleted(bson.M{"_id": eventObjectID})).Decode(&event)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			utils.ErrorResponse(c, 404, "Event not found")
@@ -315,33 +345,43 @@ func (ec *EventController) InviteToEvent(c *gin.Context) {
 		return
 	}
 
-	// Check if user is organizer
-	isOrganizer := false
-	for _, p := range event.Participants {
-		if p.UserID == userObjectID && p.Role == models.RoleOrganizer {
-			isOrganizer = true
-			break
-		}
+	// Check if user may do this (organizer, or platform staff)
+	if !policies.CanInvite(actor, &event) {
+		utils.ErrorResponse(c, 403, "Only event organizers can invite users")
+		return
+	}
+	if !checkNotCancelled(c, &event) || !checkIfMatch(c, &event) {
+		return
 	}
 
-	if !isOrganizer {
-		utils.ErrorResponse(c, 403, "Only event organizers can invite users")
+	if len(req.UserIDs) == 0 && len(req.Emails) == 0 {
+		utils.ErrorResponse(c, 400, "Provide user_ids or emails to invite")
+		return
+	}
+
+	// Nonexistent accounts must not end up as participants
+	userIDs, err := existingUserIDs(req.UserIDs)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to fetch users")
+		return
+	}
+	if len(userIDs) != len(uniqueObjectIDs(req.UserIDs)) {
+		utils.ErrorResponse(c, 400, "One or more users do not exist")
+		return
+	}
+
+	// Addresses with an account are invited as users, the others get a pending invitation
+	registeredIDs, guestEmails, err := splitInviteEmails(req.Emails)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to fetch users")
 		return
 	}
+	userIDs = append(userIDs, registeredIDs...)
 
 	// Add new participants as attendees
 	newParticipants := []models.EventParticipant{}
-	for _, inviteUserID := range req.UserIDs {
-		// Check if user is already invited
-		alreadyInvited := false
-		for _, p := range event.Participants {
-			if p.UserID == inviteUserID {
-				alreadyInvited = true
-				break
-			}
-		}
-
-		if !alreadyInvited {
+	for _, inviteUserID := range uniqueObjectIDs(userIDs) {
+		if findParticipant(&event, inviteUserID) == nil {
 			newParticipants = append(newParticipants, models.EventParticipant{
 				UserID: inviteUserID,
 				Role:   models.RoleAttendee,
@@ -349,25 +389,47 @@ func (ec *EventController) InviteToEvent(c *gin.Context) {
 		}
 	}
 
-	if len(newParticipants) == 0 {
+	if len(newParticipants) == 0 && len(guestEmails) == 0 {
 		utils.ErrorResponse(c, 400, "All users are already invited to this event")
 		return
 	}
 
-	// Update event with new participants
-	_, err = collection.UpdateOne(
-		context.TODO(),
-		bson.M{"_id": eventObjectID},
-		bson.M{"$push": bson.M{"participants": bson.M{"$each": newParticipants}}, "$set": bson.M{"updated_at": time.Now()}},
-	)
+	if len(newParticipants) > 0 {
+		// Update event with new participants (only the version that was checked)
+		result, err := collection.UpdateOne(
+			context.TODO(),
+			pinVersion(bson.M{"_id": eventObjectID}, event.Version),
+			bumpVersion(bson.M{"$push": bson.M{"participants": bson.M{"$each": newParticipants}}, "$set": bson.M{"updated_at": time.Now()}}),
+		)
 
-	if err != nil {
-		utils.ErrorResponse(c, 500, "Failed to invite users")
-		return
+		if err != nil {
+			utils.ErrorResponse(c, 500, "Failed to invite users")
+			return
+		}
+		if result.MatchedCount == 0 {
+			versionConflict(c)
+			return
+		}
+		event.Version++
+		c.Header("ETag", eventETag(&event))
 	}
 
+	// Inviting a guest again sends a fresh link
+	for _, email := range guestEmails {
+		if err := inviteGuest(&event, email, actor.UserID); err != nil {
+			utils.ErrorResponse(c, 500, "Failed to invite "+email)
+			return
+		}
+	}
+	var historyDetails map[string]interface{}
+	if len(guestEmails) > 0 {
+		historyDetails = map[string]interface{}{"guest_emails": guestEmails}
+	}
+	recordEventChange(c, models.HistoryInvited, &event, event.ID, historyDetails)
+
 	utils.SuccessResponse(c, 200, "Users invited successfully", gin.H{
 		"invited_count": len(newParticipants),
+		"guest_count":   len(guestEmails),
 	})
 }
 
@@ -393,21 +455,8 @@ func (ec *EventController) UpdateEvent(c *gin.Context) {
 		return
 	}
 
-	userIDInterface, exists := c.Get("user_id")
-	if !exists {
-		utils.ErrorResponse(c, 401, "User ID not found in token")
-		return
-	}
-
-	userID, ok := userIDInterface.(string)
+	actor, ok := currentActor(c)
 	if !ok {
-		utils.ErrorResponse(c, 401, "Invalid user ID format")
-		return
-	}
-
-	userObjectID, err := primitive.ObjectIDFromHex(userID)
-	if err != nil {
-		utils.ErrorResponse(c, 400, "Invalid user ID")
 		return
 	}
 
@@ -415,7 +464,7 @@ func (ec *EventController) UpdateEvent(c *gin.Context) {
 	var event models.Event
 
 	// Find event and check if user is organizer
-	err = collection.FindOne(context.TODO(), bson.M{"_id": eventObjectID}).Decode(&event)
+	err = collection.FindOne(context.TODO(), notDeleted(bson.M{"_id": eventObjectID})).Decode(&event)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			utils.ErrorResponse(c, 404, "Event not found")
@@ -425,18 +474,46 @@ func (ec *EventController) UpdateEvent(c *gin.Context) {
 		return
 	}
 
-	// Check if user is organizer
-	isOrganizer := false
-	for _, p := range event.Participants {
-		if p.UserID == userObjectID && p.Role == models.RoleOrganizer {
-			isOrganizer = true
-			break
+	// Check if user may do this (organizer, or platform staff)
+	if !policies.CanEditEvent(actor, &event) {
+		utils.ErrorResponse(c, 403, "Only event organizers can update events")
+		return
+	}
+	if !checkNotCancelled(c, &event) || !checkIfMatch(c, &event) {
+		return
+	}
+
+	// Recurring events can be changed for one occurrence, this and following, or all
+	scope := req.Scope
+	if scope == "" {
+		scope = models.ScopeAllOccurrences
+	}
+	if scope != models.ScopeAllOccurrences {
+		if event.Recurrence == nil {
+			utils.ErrorResponse(c, 400, "Only recurring events can be changed per occurrence")
+			return
+		}
+		if req.OccurrenceDate == "" {
+			utils.ErrorResponse(c, 400, "occurrence_date is required for this scope")
+			return
+		}
+		if !checkOccurrence(c, &event, req.OccurrenceDate) {
+			return
+		}
+		if scope == models.ScopeThisOccurrence && (req.Recurrence != nil || req.Timezone != "" || req.Capacity != nil || req.Visibility != "") {
+			utils.ErrorResponse(c, 400, "The recurrence, timezone, capacity and visibility cannot be changed for a single occurrence")
+			return
 		}
 	}
 
-	if !isOrganizer {
-		utils.ErrorResponse(c, 403, "Only event organizers can update events")
-		return
+	// New times are relative to the times being changed (the occurrence's for "this" and "following")
+	startAt, endAt := event.StartAt, event.EndAt
+	switch scope {
+	case models.ScopeThisOccurrence:
+		occurrence := occurrenceResponse(&event, req.OccurrenceDate, findOverride(&event, req.OccurrenceDate))
+		startAt, endAt = occurrence.StartAt, occurrence.EndAt
+	case models.ScopeFollowing:
+		startAt, endAt = occurrenceTimes(&event, req.OccurrenceDate)
 	}
 
 	// Build update document with only provided fields
@@ -447,43 +524,67 @@ func (ec *EventController) UpdateEvent(c *gin.Context) {
 	if req.Description != "" {
 		updateDoc["description"] = req.Description
 	}
-	if req.Date != "" {
-		// Validate date is not in the past
-		eventDate, err := time.Parse("2006-01-02", req.Date)
-		if err != nil {
-			utils.ErrorResponse(c, 400, "Invalid date format. Use YYYY-MM-DD")
-			return
-		}
+	if req.Location != "" {
+		updateDoc["location"] = req.Location
+	}
+	if req.Capacity != nil {
+		updateDoc["capacity"] = *req.Capacity
+	}
+	if req.Visibility != "" {
+		updateDoc["visibility"] = req.Visibility
+	}
+	if !readEventTimes(c, req, startAt, endAt, event.Timezone, updateDoc) {
+		return
+	}
 
-		// Compare with today (start of day)
-		now := time.Now()
-		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch {
+	case scope == models.ScopeThisOccurrence:
+		updateOccurrence(c, &event, req.OccurrenceDate, updateDoc)
+		return
+	case scope == models.ScopeFollowing && req.OccurrenceDate != event.LocalDate():
+		splitSeries(c, &event, req, updateDoc)
+		return
+	}
 
-		if eventDate.Before(today) {
-			utils.ErrorResponse(c, 400, "Event date cannot be in the past")
+	// A new first date or rule must still describe a valid series
+	updated := event
+	applyEventChanges(&updated, updateDoc)
+	if req.Recurrence != nil || (event.Recurrence != nil && updated.LocalDate() != event.LocalDate()) {
+		rec := req.Recurrence
+		if rec == nil {
+			current := *event.Recurrence
+			rec = &current
+		}
+
+		if err := normalizeRecurrence(rec, updated.LocalDate()); err != nil {
+			utils.ErrorResponse(c, 400, "Invalid recurrence: "+err.Error())
 			return
 		}
-		updateDoc["date"] = req.Date
-	}
-	if req.Time != "" {
-		updateDoc["time"] = req.Time
-	}
-	if req.Location != "" {
-		updateDoc["location"] = req.Location
+		updateDoc["recurrence"] = rec
 	}
 	updateDoc["updated_at"] = time.Now()
 
-	_, err = collection.UpdateOne(
+	// Only the version that was read is updated, so concurrent edits cannot overwrite each other
+	result, err := collection.UpdateOne(
 		context.TODO(),
-		bson.M{"_id": eventObjectID},
-		bson.M{"$set": updateDoc},
+		pinVersion(bson.M{"_id": eventObjectID}, event.Version),
+		bumpVersion(bson.M{"$set": updateDoc}),
 	)
 
 	if err != nil {
 		utils.ErrorResponse(c, 500, "Failed to update event")
 		return
 	}
+	if result.MatchedCount == 0 {
+		versionConflict(c)
+		return
+	}
+	recordEventChange(c, models.HistoryUpdated, &event, event.ID, nil)
+
+	capacityChanged(&event, &updated)
 
+	updated.Version = event.Version + 1
+	c.Header("ETag", eventETag(&updated))
 	utils.SuccessResponse(c, 200, "Event updated successfully", nil)
 }
 
@@ -497,21 +598,8 @@ func (ec *EventController) DeleteEvent(c *gin.Context) {
 		return
 	}
 
-	userIDInterface, exists := c.Get("user_id")
-	if !exists {
-		utils.ErrorResponse(c, 401, "User ID not found in token")
-		return
-	}
-
-	userID, ok := userIDInterface.(string)
+	actor, ok := currentActor(c)
 	if !ok {
-		utils.ErrorResponse(c, 401, "Invalid user ID format")
-		return
-	}
-
-	userObjectID, err := primitive.ObjectIDFromHex(userID)
-	if err != nil {
-		utils.ErrorResponse(c, 400, "Invalid user ID")
 		return
 	}
 
@@ -519,7 +607,7 @@ func (ec *EventController) DeleteEvent(c *gin.Context) {
 	var event models.Event
 
 	// Find event and check if user is organizer
-	err = collection.FindOne(context.TODO(), bson.M{"_id": eventObjectID}).Decode(&event)
+	err = collection.FindOne(context.TODO(), notDeleted(bson.M{"_id": eventObjectID})).Decode(&event)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			utils.ErrorResponse(c, 404, "Event not found")
@@ -529,35 +617,71 @@ func (ec *EventController) DeleteEvent(c *gin.Context) {
 		return
 	}
 
-	// Check if user is organizer
-	isOrganizer := false
-	for _, p := range event.Participants {
-		if p.UserID == userObjectID && p.Role == models.RoleOrganizer {
-			isOrganizer = true
-			break
+	// Check if user may do this (organizer, or platform staff); co-organizers may remove occurrences
+	if !policies.CanEditEvent(actor, &event) {
+		utils.ErrorResponse(c, 403, "Only event organizers can delete events")
+		return
+	}
+	if !checkIfMatch(c, &event) {
+		return
+	}
+
+	// Recurring events can lose a single occurrence or every occurrence from a date on
+	scope := c.DefaultQuery("scope", models.ScopeAllOccurrences)
+	switch scope {
+	case models.ScopeThisOccurrence, models.ScopeFollowing:
+		occurrenceDate := c.Query("occurrence_date")
+		if event.Recurrence == nil {
+			utils.ErrorResponse(c, 400, "Only recurring events can be deleted per occurrence")
+			return
+		}
+		if occurrenceDate == "" {
+			utils.ErrorResponse(c, 400, "occurrence_date is required for this scope")
+			return
+		}
+		if !checkOccurrence(c, &event, occurrenceDate) {
+			return
+		}
+
+		if scope == models.ScopeThisOccurrence {
+			deleteOccurrence(c, &event, occurrenceDate)
+			return
+		}
+		if occurrenceDate != event.LocalDate() {
+			endSeriesBefore(c, &event, occurrenceDate)
+			return
 		}
+	case models.ScopeAllOccurrences:
+	default:
+		utils.ErrorResponse(c, 400, "Invalid scope. Must be: this, following, or all")
+		return
 	}
 
-	if !isOrganizer {
-		utils.ErrorResponse(c, 403, "Only event organizers can delete events")
+	// Deleting the whole event is reserved to its owner
+	if !policies.CanDeleteEvent(actor, &event) {
+		utils.ErrorResponse(c, 403, "Only the event owner can delete the whole event")
 		return
 	}
 
-	// Delete event
-	result, err := collection.DeleteOne(context.TODO(), bson.M{"_id": eventObjectID})
+	// Soft delete: the event and its answers are kept until the purge job removes them
+	now := time.Now()
+	result, err := collection.UpdateOne(
+		context.TODO(),
+		notDeleted(pinVersion(bson.M{"_id": eventObjectID}, event.Version)),
+		bumpVersion(bson.M{"$set": bson.M{"deleted_at": now, "updated_at": now}}),
+	)
 	if err != nil {
 		utils.ErrorResponse(c, 500, "Failed to delete event")
 		return
 	}
 
-	if result.DeletedCount == 0 {
-		utils.ErrorResponse(c, 404, "Event not found")
+	if result.MatchedCount == 0 {
+		versionConflict(c)
 		return
 	}
+	recordEventChange(c, models.HistoryDeleted, &event, event.ID, nil)
 
-	// Also delete related event statuses
-	eventStatusCollection := database.GetCollection("event_statuses")
-	eventStatusCollection.DeleteMany(context.TODO(), bson.M{"event_id": eventObjectID})
-
-	utils.SuccessResponse(c, 200, "Event deleted successfully", nil)
+	utils.SuccessResponse(c, 200, fmt.Sprintf("Event deleted successfully. It can be restored for %d days", config.GetEventRestoreDays()), gin.H{
+		"restorable_until": restoreDeadline(now),
+	})
 }
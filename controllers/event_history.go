@@ -0,0 +1,313 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+	"tools-backend/database"
+	"tools-backend/models"
+	"tools-backend/policies"
+	"tools-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetEventHistory lists the changes of an event, newest first (?page= and ?limit=).
+// Attendees only see their own answers and not the addresses of invited guests; organizers see everything.
+func (ec *EventController) GetEventHistory(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	event, ok := loadEvent(c)
+	if !ok {
+		return
+	}
+
+	if !policies.CanViewHistory(actor, event) {
+		utils.ErrorResponse(c, 403, "Only participants can view the history of this event")
+		return
+	}
+
+	organizer := policies.CanEditEvent(actor, event)
+	filter := bson.M{"event_id": event.ID}
+	if !organizer {
+		filter["$or"] = bson.A{
+			bson.M{"action": bson.M{"$ne": models.HistoryStatusChanged}},
+			bson.M{"actor_id": actor.UserID},
+		}
+	}
+
+	page, limit := parsePagination(c, 20, 100)
+	collection := historyCollection()
+	total, err := collection.CountDocuments(context.TODO(), filter)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to count history entries")
+		return
+	}
+
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip((page - 1) * limit).
+		SetLimit(limit).
+		SetProjection(bson.M{"snapshot": 0})
+	cursor, err := collection.Find(context.TODO(), filter, findOptions)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to fetch history")
+		return
+	}
+	defer cursor.Close(context.TODO())
+
+	entries := []models.EventHistoryEntry{}
+	if err = cursor.All(context.TODO(), &entries); err != nil {
+		utils.ErrorResponse(c, 500, "Failed to decode history")
+		return
+	}
+	if !organizer {
+		for i := range entries {
+			if entries[i].Action == models.HistoryInvited {
+				entries[i].Details = nil
+			}
+		}
+	}
+
+	utils.SuccessResponse(c, 200, "Event history retrieved successfully", gin.H{
+		"history":    entries,
+		"pagination": paginationMeta(page, limit, total),
+	})
+}
+
+// RevertEvent puts the details of an event (title, description, times, location, capacity,
+// visibility, recurrence) back to how they were at a version; participants and answers stay
+func (ec *EventController) RevertEvent(c *gin.Context) {
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version < 1 {
+		utils.ErrorResponse(c, 400, "Invalid version")
+		return
+	}
+
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	event, ok := loadEvent(c)
+	if !ok {
+		return
+	}
+
+	if !policies.CanEditEvent(actor, event) {
+		utils.ErrorResponse(c, 403, "Only event organizers can revert events")
+		return
+	}
+	if !checkNotCancelled(c, event) || !checkIfMatch(c, event) {
+		return
+	}
+
+	var entry models.EventHistoryEntry
+	err = historyCollection().FindOne(context.TODO(), bson.M{"event_id": event.ID, "version": version}).Decode(&entry)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			utils.ErrorResponse(c, 404, "Version not found")
+		} else {
+			utils.ErrorResponse(c, 500, "Failed to fetch version")
+		}
+		return
+	}
+	if entry.Snapshot == nil {
+		utils.ErrorResponse(c, 400, "This version cannot be restored")
+		return
+	}
+
+	snapshot := entry.Snapshot
+	set := bson.M{
+		"title":       snapshot.Title,
+		"description": snapshot.Description,
+		"location":    snapshot.Location,
+		"start_at":    snapshot.StartAt,
+		"end_at":      snapshot.EndAt,
+		"timezone":    snapshot.Timezone,
+		"updated_at":  time.Now(),
+	}
+	unset := bson.M{}
+	if snapshot.Capacity > 0 {
+		set["capacity"] = snapshot.Capacity
+	} else {
+		unset["capacity"] = ""
+	}
+	if snapshot.Visibility != "" {
+		set["visibility"] = snapshot.Visibility
+	} else {
+		unset["visibility"] = ""
+	}
+	if snapshot.Recurrence != nil {
+		set["recurrence"] = snapshot.Recurrence
+	} else {
+		unset["recurrence"] = ""
+	}
+	if len(snapshot.Overrides) > 0 {
+		set["overrides"] = snapshot.Overrides
+	} else {
+		unset["overrides"] = ""
+	}
+
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+	result, err := database.GetCollection("events").UpdateOne(context.TODO(), pinVersion(bson.M{"_id": event.ID}, event.Version), bumpVersion(update))
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to revert event")
+		return
+	}
+	if result.MatchedCount == 0 {
+		versionConflict(c)
+		return
+	}
+	recordEventChange(c, models.HistoryReverted, event, event.ID, map[string]interface{}{"version": version})
+
+	reverted := *event
+	reverted.Title, reverted.Description, reverted.Location = snapshot.Title, snapshot.Description, snapshot.Location
+	reverted.StartAt, reverted.EndAt, reverted.Timezone = snapshot.StartAt, snapshot.EndAt, snapshot.Timezone
+	reverted.Capacity, reverted.Visibility = snapshot.Capacity, snapshot.Visibility
+	reverted.Recurrence, reverted.Overrides = snapshot.Recurrence, snapshot.Overrides
+	reverted.Version = event.Version + 1
+
+	capacityChanged(event, &reverted)
+
+	c.Header("ETag", eventETag(&reverted))
+	utils.SuccessResponse(c, 200, "Event reverted to version "+strconv.Itoa(version), localizeEvent(c, reverted.ToResponse()))
+}
+
+// recordEventChange adds a history entry for a change made to an event by the current user,
+// diffing the event as it was (nil for a new event) with how it is stored now. Failures are
+// logged but never fail the request.
+func recordEventChange(c *gin.Context, action string, before *models.Event, eventID primitive.ObjectID, details map[string]interface{}) {
+	if entry, ok := eventChangeEntry(action, before, eventID, details); ok {
+		addHistoryEntry(c, entry)
+	}
+}
+
+// eventChangeEntry builds the history entry of a change (false when there is nothing to record)
+func eventChangeEntry(action string, before *models.Event, eventID primitive.ObjectID, details map[string]interface{}) (models.EventHistoryEntry, bool) {
+	var after models.Event
+	if err := database.GetCollection("events").FindOne(context.TODO(), bson.M{"_id": eventID}).Decode(&after); err != nil {
+		log.Printf("Failed to load event %s for its history: %v", eventID.Hex(), err)
+		return models.EventHistoryEntry{}, false
+	}
+
+	changes := diffEvents(before, &after)
+	if len(changes) == 0 && action == models.HistoryUpdated {
+		return models.EventHistoryEntry{}, false
+	}
+
+	return models.EventHistoryEntry{
+		EventID:  eventID,
+		Version:  after.Version,
+		Action:   action,
+		Changes:  changes,
+		Details:  details,
+		Snapshot: &after,
+	}, true
+}
+
+// recordStatusChange adds a history entry for an answer, which is stored apart from the event
+// (so it has neither a version nor a snapshot to revert to)
+func recordStatusChange(c *gin.Context, event *models.Event, from, to models.EventStatusValue, details map[string]interface{}) {
+	if from == "" {
+		from = models.StatusNoResponse
+	}
+	if from == to {
+		return
+	}
+
+	addHistoryEntry(c, models.EventHistoryEntry{
+		EventID: event.ID,
+		Action:  models.HistoryStatusChanged,
+		Changes: []models.FieldChange{{Field: "status", Old: from, New: to}},
+		Details: details,
+	})
+}
+
+// addHistoryEntry stores an entry for the current user (unless the entry names its actor). Two changes made at the same time can both
+// read the event at the later version; the unique index on (event_id, version) keeps that version
+// for the first entry and the other one is stored without a version.
+func addHistoryEntry(c *gin.Context, entry models.EventHistoryEntry) {
+	if actorID, err := primitive.ObjectIDFromHex(c.GetString("user_id")); err == nil && entry.ActorID == nil {
+		entry.ActorID = &actorID
+	}
+	if entry.Changes == nil {
+		entry.Changes = []models.FieldChange{}
+	}
+	entry.CreatedAt = time.Now()
+
+	collection := historyCollection()
+	_, err := collection.InsertOne(context.TODO(), entry)
+	if mongo.IsDuplicateKeyError(err) {
+		entry.Version = 0
+		_, err = collection.InsertOne(context.TODO(), entry)
+	}
+	if err != nil {
+		log.Printf("Failed to record the history of event %s: %v", entry.EventID.Hex(), err)
+	}
+}
+
+// diffEvents lists the fields that differ between two versions of an event
+func diffEvents(before, after *models.Event) []models.FieldChange {
+	old, current := eventFields(before), eventFields(after)
+
+	fields := []string{}
+	for field := range old {
+		fields = append(fields, field)
+	}
+	for field := range current {
+		if _, ok := old[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+
+	changes := []models.FieldChange{}
+	for _, field := range fields {
+		switch field {
+		case "id", "version", "created_at", "updated_at":
+			continue
+		}
+		if !reflect.DeepEqual(old[field], current[field]) {
+			changes = append(changes, models.FieldChange{Field: field, Old: old[field], New: current[field]})
+		}
+	}
+	return changes
+}
+
+// eventFields returns the fields of an event as the API shows them (empty for nil)
+func eventFields(event *models.Event) map[string]interface{} {
+	fields := map[string]interface{}{}
+	if event == nil {
+		return fields
+	}
+
+	data, err := json.Marshal(event)
+	if err == nil {
+		err = json.Unmarshal(data, &fields)
+	}
+	if err != nil {
+		log.Printf("Failed to compare versions of event %s: %v", event.ID.Hex(), err)
+	}
+	return fields
+}
+
+// historyCollection returns the event_history collection, decoding nested values as maps so
+// the old and new values render as plain JSON
+func historyCollection() *mongo.Collection {
+	return database.DB.Collection("event_history", options.Collection().SetBSONOptions(&options.BSONOptions{DefaultDocumentM: true}))
+}
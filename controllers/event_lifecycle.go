@@ -0,0 +1,310 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+	"tools-backend/config"
+	"tools-backend/database"
+	"tools-backend/mailer"
+	"tools-backend/models"
+	"tools-backend/policies"
+	"tools-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CancelEvent marks an event as cancelled: it stays visible with the reason and every
+// participant and invited guest is told
+func (ec *EventController) CancelEvent(c *gin.Context) {
+	var req models.CancelEventRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, "Invalid request data")
+		return
+	}
+
+	// Validate request
+	if errors := utils.ValidateStruct(req); len(errors) > 0 {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	event, ok := loadEvent(c)
+	if !ok {
+		return
+	}
+
+	if !policies.CanCancelEvent(actor, event) {
+		utils.ErrorResponse(c, 403, "Only the event owner can cancel the event")
+		return
+	}
+	if !checkNotCancelled(c, event) || !checkIfMatch(c, event) {
+		return
+	}
+
+	now := time.Now()
+	result, err := database.GetCollection("events").UpdateOne(
+		context.TODO(),
+		pinVersion(notDeleted(bson.M{"_id": event.ID, "cancelled_at": bson.M{"$exists": false}}), event.Version),
+		bumpVersion(bson.M{"$set": bson.M{"cancelled_at": now, "cancellation_reason": req.Reason, "updated_at": now}}),
+	)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to cancel event")
+		return
+	}
+	if result.MatchedCount == 0 {
+		versionConflict(c)
+		return
+	}
+	recordEventChange(c, models.HistoryCancelled, event, event.ID, nil)
+
+	event.CancelledAt = &now
+	event.CancellationReason = req.Reason
+	event.UpdatedAt = now
+	event.Version++
+	sendEventCancelled(event, actor.UserID)
+
+	c.Header("ETag", eventETag(event))
+
+	utils.SuccessResponse(c, 200, "Event cancelled successfully", localizeEvent(c, event.ToResponse()))
+}
+
+// ListDeletedEvents returns the deleted events the current user owns that can still be restored
+func (ec *EventController) ListDeletedEvents(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	filter := bson.M{
+		"deleted_at":           bson.M{"$exists": true},
+		"participants.user_id": actor.UserID,
+	}
+	findOptions := options.Find().SetSort(bson.D{{Key: "deleted_at", Value: -1}})
+
+	cursor, err := database.GetCollection("events").Find(context.TODO(), filter, findOptions)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to fetch events")
+		return
+	}
+	defer cursor.Close(context.TODO())
+
+	var events []models.Event
+	if err = cursor.All(context.TODO(), &events); err != nil {
+		utils.ErrorResponse(c, 500, "Failed to process events")
+		return
+	}
+
+	eventResponses := []models.EventResponse{}
+	for _, event := range events {
+		if policies.IsOwner(&event, actor.UserID) {
+			eventResponses = append(eventResponses, event.ToResponse())
+		}
+	}
+
+	utils.SuccessResponse(c, 200, "Deleted events retrieved successfully", gin.H{
+		"events":       localizeEvents(c, eventResponses),
+		"restore_days": config.GetEventRestoreDays(),
+	})
+}
+
+// RestoreEvent brings back a deleted event with its answers, as long as it has not been purged
+func (ec *EventController) RestoreEvent(c *gin.Context) {
+	eventObjectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, 400, "Invalid event ID")
+		return
+	}
+
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	collection := database.GetCollection("events")
+	var event models.Event
+	err = collection.FindOne(context.TODO(), bson.M{"_id": eventObjectID, "deleted_at": bson.M{"$exists": true}}).Decode(&event)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			utils.ErrorResponse(c, 404, "Deleted event not found")
+		} else {
+			utils.ErrorResponse(c, 500, "Failed to fetch event")
+		}
+		return
+	}
+
+	if !policies.CanDeleteEvent(actor, &event) {
+		utils.ErrorResponse(c, 403, "Only the event owner can restore the event")
+		return
+	}
+	if time.Now().After(restoreDeadline(*event.DeletedAt)) {
+		utils.ErrorResponse(c, 410, "This event can no longer be restored")
+		return
+	}
+	if !checkIfMatch(c, &event) {
+		return
+	}
+
+	result, err := collection.UpdateOne(
+		context.TODO(),
+		pinVersion(bson.M{"_id": event.ID, "deleted_at": event.DeletedAt}, event.Version),
+		bumpVersion(bson.M{"$unset": bson.M{"deleted_at": ""}, "$set": bson.M{"updated_at": time.Now()}}),
+	)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to restore event")
+		return
+	}
+	if result.MatchedCount == 0 {
+		versionConflict(c)
+		return
+	}
+	recordEventChange(c, models.HistoryRestored, &event, event.ID, nil)
+
+	event.DeletedAt = nil
+	event.Version++
+	c.Header("ETag", eventETag(&event))
+	utils.SuccessResponse(c, 200, "Event restored successfully", localizeEvent(c, event.ToResponse()))
+}
+
+// StartEventPurge removes deleted events past their restore window in the background,
+// once at startup and then every EVENT_PURGE_INTERVAL
+func StartEventPurge() {
+	go func() {
+		ticker := time.NewTicker(config.GetEventPurgeInterval())
+		defer ticker.Stop()
+		for {
+			purgeDeletedEvents()
+			<-ticker.C
+		}
+	}()
+}
+
+// purgeDeletedEvents permanently removes the events deleted longer ago than the restore window
+func purgeDeletedEvents() {
+	cutoff := time.Now().AddDate(0, 0, -config.GetEventRestoreDays())
+	findOptions := options.Find().SetProjection(bson.M{"_id": 1})
+	cursor, err := database.GetCollection("events").Find(context.TODO(), bson.M{"deleted_at": bson.M{"$lte": cutoff}}, findOptions)
+	if err != nil {
+		log.Printf("Failed to find deleted events to purge: %v", err)
+		return
+	}
+
+	var events []models.Event
+	err = cursor.All(context.TODO(), &events)
+	cursor.Close(context.TODO())
+	if err != nil {
+		log.Printf("Failed to find deleted events to purge: %v", err)
+		return
+	}
+	if len(events) == 0 {
+		return
+	}
+
+	eventIDs := make([]primitive.ObjectID, 0, len(events))
+	for _, event := range events {
+		eventIDs = append(eventIDs, event.ID)
+	}
+	if err := purgeEvents(eventIDs); err != nil {
+		log.Printf("Failed to purge deleted events: %v", err)
+		return
+	}
+	log.Printf("Purged %d deleted events", len(eventIDs))
+}
+
+// purgeEvents permanently removes events with their answers, seat counters, invitations, invite
+// links and history. The related data goes first so a failure never leaves it without its event.
+func purgeEvents(eventIDs []primitive.ObjectID) error {
+	related := bson.M{"event_id": bson.M{"$in": eventIDs}}
+	for _, name := range []string{"event_statuses", "event_seats", "event_invitations", "event_invite_links", "event_history"} {
+		if _, err := database.GetCollection(name).DeleteMany(context.TODO(), related); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	_, err := database.GetCollection("events").DeleteMany(context.TODO(), bson.M{"_id": bson.M{"$in": eventIDs}})
+	return err
+}
+
+// restoreDeadline returns until when an event deleted at deletedAt can be restored
+func restoreDeadline(deletedAt time.Time) time.Time {
+	return deletedAt.AddDate(0, 0, config.GetEventRestoreDays())
+}
+
+// notDeleted restricts a filter to events that have not been deleted
+func notDeleted(filter bson.M) bson.M {
+	filter["deleted_at"] = bson.M{"$exists": false}
+	return filter
+}
+
+// checkNotCancelled refuses changes to a cancelled event (writes the error response)
+func checkNotCancelled(c *gin.Context, event *models.Event) bool {
+	if event.CancelledAt != nil {
+		utils.ErrorResponse(c, 400, "This event has been cancelled")
+		return false
+	}
+	return true
+}
+
+// sendEventCancelled tells the participants (except the one who cancelled) and the invited
+// guests that an event was cancelled, with the reason
+func sendEventCancelled(event *models.Event, cancelledBy primitive.ObjectID) {
+	userIDs := make([]primitive.ObjectID, 0, len(event.Participants))
+	for _, p := range event.Participants {
+		if p.UserID != cancelledBy {
+			userIDs = append(userIDs, p.UserID)
+		}
+	}
+
+	recipients := []string{}
+	if len(userIDs) > 0 {
+		cursor, err := database.GetCollection("users").Find(context.TODO(), bson.M{"_id": bson.M{"$in": userIDs}})
+		if err != nil {
+			log.Printf("Failed to load the participants of event %s: %v", event.ID.Hex(), err)
+		} else {
+			var users []models.User
+			if err := cursor.All(context.TODO(), &users); err != nil {
+				log.Printf("Failed to load the participants of event %s: %v", event.ID.Hex(), err)
+			}
+			cursor.Close(context.TODO())
+			for _, user := range users {
+				recipients = append(recipients, user.Email)
+			}
+		}
+	}
+
+	cursor, err := database.GetCollection("event_invitations").Find(context.TODO(), bson.M{"event_id": event.ID})
+	if err != nil {
+		log.Printf("Failed to load the invitations of event %s: %v", event.ID.Hex(), err)
+	} else {
+		var invitations []models.EventInvitation
+		if err := cursor.All(context.TODO(), &invitations); err != nil {
+			log.Printf("Failed to load the invitations of event %s: %v", event.ID.Hex(), err)
+		}
+		cursor.Close(context.TODO())
+		for _, invitation := range invitations {
+			recipients = append(recipients, invitation.Email)
+		}
+	}
+
+	for _, email := range recipients {
+		err := mailer.Send(mailer.Message{
+			To:      email,
+			Subject: "Cancelled: " + event.Title,
+			Body: fmt.Sprintf("Hello,\n\n\"%s\", planned on %s at %s, has been cancelled.\n\nReason: %s",
+				event.Title, event.StartAt.In(event.Zone()).Format("Monday 2 January 2006, 15:04 MST"), event.Location, event.CancellationReason),
+		})
+		if err != nil {
+			log.Printf("Failed to send cancellation email to %s: %v", email, err)
+		}
+	}
+}
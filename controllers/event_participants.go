@@ -0,0 +1,379 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+	"tools-backend/config"
+	"tools-backend/database"
+	"tools-backend/mailer"
+	"tools-backend/models"
+	"tools-backend/policies"
+	"tools-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// UpdateParticipantRole promotes an attendee to co-organizer or demotes an organizer.
+// The owner cannot be demoted, so an event always keeps at least one organizer.
+func (ec *EventController) UpdateParticipantRole(c *gin.Context) {
+	var req models.UpdateParticipantRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, "Invalid request data")
+		return
+	}
+
+	// Validate request
+	if errors := utils.ValidateStruct(req); len(errors) > 0 {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	participantID, err := primitive.ObjectIDFromHex(c.Param("userId"))
+	if err != nil {
+		utils.ErrorResponse(c, 400, "Invalid user ID")
+		return
+	}
+
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	event, ok := loadEvent(c)
+	if !ok {
+		return
+	}
+
+	participant := findParticipant(event, participantID)
+	if participant == nil {
+		utils.ErrorResponse(c, 404, "User is not a participant of this event")
+		return
+	}
+	if participant.Role == req.Role {
+		utils.ErrorResponse(c, 400, "User already has this role")
+		return
+	}
+
+	if req.Role == models.RoleOrganizer {
+		if !policies.CanPromote(actor, event) {
+			utils.ErrorResponse(c, 403, "Only event organizers can promote attendees")
+			return
+		}
+	} else {
+		if policies.IsOwner(event, participantID) {
+			utils.ErrorResponse(c, 400, "The owner cannot be demoted, transfer the ownership first")
+			return
+		}
+		if !policies.CanDemote(actor, event, participantID) {
+			utils.ErrorResponse(c, 403, "Only the event owner can demote organizers")
+			return
+		}
+	}
+	if !checkIfMatch(c, event) {
+		return
+	}
+
+	// The filter pins the version, current role and owner so a concurrent change cannot leave the event without an organizer
+	filter := bson.M{
+		"_id":          event.ID,
+		"participants": bson.M{"$elemMatch": bson.M{"user_id": participantID, "role": participant.Role}},
+	}
+	set := bson.M{"participants.$.role": req.Role, "updated_at": time.Now()}
+	pinOwner(event, filter, set)
+
+	result, err := database.GetCollection("events").UpdateOne(context.TODO(), pinVersion(filter, event.Version), bumpVersion(bson.M{"$set": set}))
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to update participant role")
+		return
+	}
+	if result.MatchedCount == 0 {
+		versionConflict(c)
+		return
+	}
+
+	recordEventChange(c, models.HistoryRoleChanged, event, event.ID, map[string]interface{}{"user_id": participantID})
+
+	event.Version++
+	c.Header("ETag", eventETag(event))
+
+	utils.SuccessResponse(c, 200, "Participant role updated successfully", gin.H{
+		"user_id": participantID,
+		"role":    req.Role,
+	})
+}
+
+// TransferOwnership hands the event over to another participant, who becomes an organizer
+// if needed. The previous owner stays an organizer and can then step down.
+func (ec *EventController) TransferOwnership(c *gin.Context) {
+	var req models.TransferOwnershipRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, "Invalid request data")
+		return
+	}
+
+	// Validate request
+	if errors := utils.ValidateStruct(req); len(errors) > 0 {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	event, ok := loadEvent(c)
+	if !ok {
+		return
+	}
+
+	if !policies.CanTransferOwnership(actor, event) {
+		utils.ErrorResponse(c, 403, "Only the event owner can transfer the ownership")
+		return
+	}
+	if !checkIfMatch(c, event) {
+		return
+	}
+	if policies.IsOwner(event, req.UserID) {
+		utils.ErrorResponse(c, 400, "User already owns this event")
+		return
+	}
+	if findParticipant(event, req.UserID) == nil {
+		utils.ErrorResponse(c, 400, "The new owner must be a participant of this event")
+		return
+	}
+
+	filter := bson.M{"_id": event.ID, "participants.user_id": req.UserID}
+	set := bson.M{"participants.$.role": models.RoleOrganizer, "updated_at": time.Now()}
+	pinOwner(event, filter, set)
+	set["owner_id"] = req.UserID
+
+	result, err := database.GetCollection("events").UpdateOne(context.TODO(), pinVersion(filter, event.Version), bumpVersion(bson.M{"$set": set}))
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to transfer ownership")
+		return
+	}
+	if result.MatchedCount == 0 {
+		versionConflict(c)
+		return
+	}
+
+	recordEventChange(c, models.HistoryOwnershipTransferred, event, event.ID, nil)
+
+	event.Version++
+	c.Header("ETag", eventETag(event))
+
+	utils.SuccessResponse(c, 200, "Ownership transferred successfully", gin.H{
+		"owner_id":          req.UserID,
+		"previous_owner_id": event.Owner(),
+	})
+}
+
+// RemoveParticipant uninvites a participant (e.g. invited by mistake); their answers are deleted
+// and a seat they held goes to the waitlist. The owner cannot be removed.
+func (ec *EventController) RemoveParticipant(c *gin.Context) {
+	participantID, err := primitive.ObjectIDFromHex(c.Param("userId"))
+	if err != nil {
+		utils.ErrorResponse(c, 400, "Invalid user ID")
+		return
+	}
+
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	event, ok := loadEvent(c)
+	if !ok {
+		return
+	}
+
+	participant := findParticipant(event, participantID)
+	if participant == nil {
+		utils.ErrorResponse(c, 404, "User is not a participant of this event")
+		return
+	}
+	if policies.IsOwner(event, participantID) {
+		utils.ErrorResponse(c, 400, "The owner cannot be removed, transfer the ownership first")
+		return
+	}
+	if !policies.CanRemoveParticipant(actor, event, *participant) {
+		if participant.Role == models.RoleOrganizer {
+			utils.ErrorResponse(c, 403, "Only the event owner can remove organizers")
+		} else {
+			utils.ErrorResponse(c, 403, "Only event organizers can remove participants")
+		}
+		return
+	}
+	if !checkIfMatch(c, event) {
+		return
+	}
+
+	if !removeParticipant(c, event, participantID) {
+		return
+	}
+	recordEventChange(c, models.HistoryParticipantRemoved, event, event.ID, map[string]interface{}{"user_id": participantID})
+
+	event.Version++
+	c.Header("ETag", eventETag(event))
+
+	if participantID != actor.UserID {
+		sendParticipantRemoved(event, participantID)
+	}
+
+	utils.SuccessResponse(c, 200, "Participant removed successfully", nil)
+}
+
+// LeaveEvent removes the current user from an event they were invited to, so it no longer
+// shows in their invited events. The owner has to transfer the ownership before leaving.
+func (ec *EventController) LeaveEvent(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	event, ok := loadEvent(c)
+	if !ok {
+		return
+	}
+
+	if findParticipant(event, actor.UserID) == nil {
+		utils.ErrorResponse(c, 404, "You are not a participant of this event")
+		return
+	}
+	if policies.IsOwner(event, actor.UserID) {
+		utils.ErrorResponse(c, 400, "The owner cannot leave the event, transfer the ownership first")
+		return
+	}
+
+	if !removeParticipant(c, event, actor.UserID) {
+		return
+	}
+	recordEventChange(c, models.HistoryLeft, event, event.ID, nil)
+
+	sendParticipantLeft(event, actor.UserID)
+
+	utils.SuccessResponse(c, 200, "You left the event", nil)
+}
+
+// removeParticipant pulls the user from the event, frees their seats and deletes their answers
+// (writes the error response when it fails)
+func removeParticipant(c *gin.Context, event *models.Event, userID primitive.ObjectID) bool {
+	filter := bson.M{"_id": event.ID, "participants.user_id": userID}
+	set := bson.M{"updated_at": time.Now()}
+	pinOwner(event, filter, set)
+
+	result, err := database.GetCollection("events").UpdateOne(
+		context.TODO(),
+		pinVersion(filter, event.Version),
+		bumpVersion(bson.M{"$pull": bson.M{"participants": bson.M{"user_id": userID}}, "$set": set}),
+	)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to remove participant")
+		return false
+	}
+	if result.MatchedCount == 0 {
+		versionConflict(c)
+		return false
+	}
+
+	// Seats the user held go to the waitlist before the remaining answers are removed
+	releaseUserSeats(userID, []primitive.ObjectID{event.ID})
+	_, err = database.GetCollection("event_statuses").DeleteMany(context.TODO(), bson.M{"event_id": event.ID, "user_id": userID})
+	if err != nil {
+		log.Printf("Failed to delete the answers of user %s to event %s: %v", userID.Hex(), event.ID.Hex(), err)
+	}
+	return true
+}
+
+// sendParticipantRemoved tells a user they were removed from an event
+func sendParticipantRemoved(event *models.Event, userID primitive.ObjectID) {
+	var user models.User
+	if err := database.GetCollection("users").FindOne(context.TODO(), bson.M{"_id": userID}).Decode(&user); err != nil {
+		log.Printf("Failed to load removed participant %s: %v", userID.Hex(), err)
+		return
+	}
+
+	err := mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "You were removed from " + event.Title,
+		Body: fmt.Sprintf("Hi %s,\n\nAn organizer removed you from \"%s\". The event no longer appears in your invitations and your answer has been deleted.",
+			user.Name, event.Title),
+	})
+	if err != nil {
+		log.Printf("Failed to send removal email to %s: %v", user.Email, err)
+	}
+}
+
+// sendParticipantLeft tells the owner of an event that a participant left it
+func sendParticipantLeft(event *models.Event, userID primitive.ObjectID) {
+	users := database.GetCollection("users")
+	var owner, user models.User
+	if err := users.FindOne(context.TODO(), bson.M{"_id": event.Owner()}).Decode(&owner); err != nil {
+		log.Printf("Failed to load the owner of event %s: %v", event.ID.Hex(), err)
+		return
+	}
+	if err := users.FindOne(context.TODO(), bson.M{"_id": userID}).Decode(&user); err != nil {
+		log.Printf("Failed to load participant %s: %v", userID.Hex(), err)
+		return
+	}
+
+	err := mailer.Send(mailer.Message{
+		To:      owner.Email,
+		Subject: user.Name + " left " + event.Title,
+		Body: fmt.Sprintf("Hi %s,\n\n%s (%s) left \"%s\" and will no longer receive updates about it.\n\n%s/api/v1/events/%s",
+			owner.Name, user.Name, user.Email, event.Title, config.GetAppURL(), event.ID.Hex()),
+	})
+	if err != nil {
+		log.Printf("Failed to send leave email to %s: %v", owner.Email, err)
+	}
+}
+
+// loadEvent finds the event of the :id route parameter (writes the error response when it cannot)
+func loadEvent(c *gin.Context) (*models.Event, bool) {
+	eventObjectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, 400, "Invalid event ID")
+		return nil, false
+	}
+
+	var event models.Event
+	err = database.GetCollection("events").FindOne(context.TODO(), notDeleted(bson.M{"_id": eventObjectID})).Decode(&event)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			utils.ErrorResponse(c, 404, "Event not found")
+		} else {
+			utils.ErrorResponse(c, 500, "Failed to fetch event")
+		}
+		return nil, false
+	}
+	return &event, true
+}
+
+// findParticipant returns the user's participation in the event, nil when not invited
+func findParticipant(event *models.Event, userID primitive.ObjectID) *models.EventParticipant {
+	for i := range event.Participants {
+		if event.Participants[i].UserID == userID {
+			return &event.Participants[i]
+		}
+	}
+	return nil
+}
+
+// pinOwner makes an update only apply while the owner is unchanged. Events created before
+// owners existed get their implicit owner (the first organizer) stored by the same update.
+func pinOwner(event *models.Event, filter, set bson.M) {
+	if event.OwnerID.IsZero() {
+		filter["owner_id"] = bson.M{"$exists": false}
+		if owner := event.Owner(); !owner.IsZero() {
+			set["owner_id"] = owner
+		}
+		return
+	}
+	filter["owner_id"] = event.OwnerID
+}
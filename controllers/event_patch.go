@@ -0,0 +1,275 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"reflect"
+	"sort"
+	"time"
+	"tools-backend/database"
+	"tools-backend/jsonpatch"
+	"tools-backend/models"
+	"tools-backend/policies"
+	"tools-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// PatchEvent changes some fields of an event (every occurrence of a recurring one) with a JSON
+// merge patch (RFC 7396, application/merge-patch+json or application/json) or a JSON Patch
+// (RFC 6902, application/json-patch+json). Unlike PUT, null removes a field and only the
+// fields the patch changes are validated.
+func (ec *EventController) PatchEvent(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	event, ok := loadEvent(c)
+	if !ok {
+		return
+	}
+
+	if !policies.CanEditEvent(actor, event) {
+		utils.ErrorResponse(c, 403, "Only event organizers can update events")
+		return
+	}
+	if !checkNotCancelled(c, event) || !checkIfMatch(c, event) {
+		return
+	}
+
+	body, err := c.GetRawData()
+	if err != nil {
+		utils.ErrorResponse(c, 400, "Invalid request data")
+		return
+	}
+
+	// Apply the patch to the event as the client sees it
+	document := eventPatchDocument(eventPatchOf(event))
+	var patched interface{}
+	switch c.ContentType() {
+	case "application/json-patch+json":
+		var operations []jsonpatch.Operation
+		if err := json.Unmarshal(body, &operations); err != nil {
+			utils.ErrorResponse(c, 400, "A JSON Patch must be an array of operations")
+			return
+		}
+		patched, err = jsonpatch.Apply(document, operations)
+		if errors.Is(err, jsonpatch.ErrTestFailed) {
+			utils.ErrorResponse(c, 409, "Invalid patch: "+err.Error())
+			return
+		}
+		if err != nil {
+			utils.ErrorResponse(c, 400, "Invalid patch: "+err.Error())
+			return
+		}
+	case "application/merge-patch+json", "application/json":
+		var patch map[string]interface{}
+		if err := json.Unmarshal(body, &patch); err != nil || patch == nil {
+			utils.ErrorResponse(c, 400, "A merge patch must be a JSON object")
+			return
+		}
+		patched = jsonpatch.MergePatch(document, patch)
+	default:
+		utils.ErrorResponse(c, 415, "Unsupported Content-Type, use application/merge-patch+json or application/json-patch+json")
+		return
+	}
+
+	result, ok := patched.(map[string]interface{})
+	if !ok {
+		utils.ErrorResponse(c, 400, "The patched event must be a JSON object")
+		return
+	}
+	for field := range result {
+		if _, known := document[field]; !known && !optionalPatchFields[field] {
+			utils.ErrorResponse(c, 400, "The field "+field+" cannot be changed with PATCH")
+			return
+		}
+	}
+
+	var patch models.EventPatch
+	data, err := json.Marshal(result)
+	if err == nil {
+		err = json.Unmarshal(data, &patch)
+	}
+	if err != nil {
+		utils.ErrorResponse(c, 400, "Invalid patch: "+err.Error())
+		return
+	}
+	patch.StartAt, patch.EndAt = patch.StartAt.UTC(), patch.EndAt.UTC()
+
+	// Only what the patch changed is validated (the same instant in another offset is no change)
+	changed := changedPatchFields(document, eventPatchDocument(patch))
+	if len(changed) == 0 {
+		c.Header("ETag", eventETag(event))
+		utils.SuccessResponse(c, 200, "Event unchanged", localizeEvent(c, event.ToResponse()))
+		return
+	}
+
+	validationErrors := utils.ValidateStructPartial(patch, changed)
+	if isChanged(changed, "recurrence") && patch.Recurrence != nil {
+		for field, message := range utils.ValidateStruct(*patch.Recurrence) {
+			validationErrors["recurrence."+field] = message
+		}
+	}
+	if len(validationErrors) > 0 {
+		utils.ValidationErrorResponse(c, validationErrors)
+		return
+	}
+
+	// Same date and time checks as CreateEvent
+	if isChanged(changed, "start_at") || isChanged(changed, "end_at") || isChanged(changed, "timezone") {
+		if !checkEventTimes(c, patch.StartAt, patch.EndAt, patch.Timezone) {
+			return
+		}
+	}
+	if isChanged(changed, "start_at") && patch.StartAt.Before(time.Now()) {
+		utils.ErrorResponse(c, 400, "Event cannot start in the past")
+		return
+	}
+
+	updated := *event
+	updated.Title, updated.Description, updated.Location = patch.Title, patch.Description, patch.Location
+	updated.StartAt, updated.EndAt, updated.Timezone = patch.StartAt, patch.EndAt, patch.Timezone
+	updated.Capacity, updated.Visibility, updated.Recurrence = patch.Capacity, patch.Visibility, patch.Recurrence
+	if updated.Visibility == "" {
+		updated.Visibility = models.VisibilityPrivate
+	}
+	if updated.Recurrence == nil {
+		updated.Overrides = nil
+	}
+
+	// A new first date or rule must still describe a valid series
+	if updated.Recurrence != nil && (isChanged(changed, "recurrence") || updated.LocalDate() != event.LocalDate()) {
+		if err := normalizeRecurrence(updated.Recurrence, updated.LocalDate()); err != nil {
+			utils.ErrorResponse(c, 400, "Invalid recurrence: "+err.Error())
+			return
+		}
+	}
+
+	updated.UpdatedAt = time.Now()
+	set := bson.M{
+		"title":       updated.Title,
+		"description": updated.Description,
+		"location":    updated.Location,
+		"start_at":    updated.StartAt,
+		"end_at":      updated.EndAt,
+		"timezone":    updated.Timezone,
+		"visibility":  updated.Visibility,
+		"updated_at":  updated.UpdatedAt,
+	}
+	unset := bson.M{}
+	if updated.Capacity > 0 {
+		set["capacity"] = updated.Capacity
+	} else {
+		unset["capacity"] = ""
+	}
+	if updated.Recurrence != nil {
+		set["recurrence"] = updated.Recurrence
+	} else {
+		unset["recurrence"] = ""
+		unset["overrides"] = ""
+	}
+
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+	updateResult, err := database.GetCollection("events").UpdateOne(
+		context.TODO(),
+		pinVersion(bson.M{"_id": event.ID}, event.Version),
+		bumpVersion(update),
+	)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to update event")
+		return
+	}
+	if updateResult.MatchedCount == 0 {
+		versionConflict(c)
+		return
+	}
+	recordEventChange(c, models.HistoryUpdated, event, event.ID, nil)
+
+	// An event that stops repeating loses the answers and seats given for single occurrences
+	stoppedRepeating := event.Recurrence != nil && updated.Recurrence == nil
+	if stoppedRepeating {
+		filter := bson.M{"event_id": event.ID, "occurrence_date": bson.M{"$nin": bson.A{nil, ""}}}
+		if _, err := database.GetCollection("event_statuses").DeleteMany(context.TODO(), filter); err != nil {
+			log.Printf("Failed to delete the occurrence answers of event %s: %v", event.ID.Hex(), err)
+		}
+		if _, err := database.GetCollection("event_seats").DeleteMany(context.TODO(), filter); err != nil {
+			log.Printf("Failed to delete the occurrence seats of event %s: %v", event.ID.Hex(), err)
+		}
+	}
+
+	capacityChanged(event, &updated)
+
+	updated.Version = event.Version + 1
+	c.Header("ETag", eventETag(&updated))
+	utils.SuccessResponse(c, 200, "Event updated successfully", localizeEvent(c, updated.ToResponse()))
+}
+
+// optionalPatchFields are the fields a patch may add although the event does not have them
+var optionalPatchFields = map[string]bool{
+	"capacity":   true,
+	"visibility": true,
+	"recurrence": true,
+}
+
+// eventPatchOf returns the fields of an event a patch can change
+func eventPatchOf(event *models.Event) models.EventPatch {
+	return models.EventPatch{
+		Title:       event.Title,
+		Description: event.Description,
+		StartAt:     event.StartAt.UTC(),
+		EndAt:       event.EndAt.UTC(),
+		Timezone:    event.Timezone,
+		Location:    event.Location,
+		Capacity:    event.Capacity,
+		Visibility:  event.VisibilityLevel(),
+		Recurrence:  event.Recurrence,
+	}
+}
+
+// eventPatchDocument returns the JSON document patches are applied to (removed fields are absent)
+func eventPatchDocument(patch models.EventPatch) map[string]interface{} {
+	document := map[string]interface{}{}
+	data, err := json.Marshal(patch)
+	if err == nil {
+		err = json.Unmarshal(data, &document)
+	}
+	if err != nil {
+		log.Printf("Failed to build the patch document of an event: %v", err)
+	}
+	return document
+}
+
+// changedPatchFields lists the fields that differ between two patch documents
+func changedPatchFields(before, after map[string]interface{}) []string {
+	changed := []string{}
+	for field, value := range after {
+		if !reflect.DeepEqual(before[field], value) {
+			changed = append(changed, field)
+		}
+	}
+	for field := range before {
+		if _, ok := after[field]; !ok {
+			changed = append(changed, field)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// isChanged reports whether a field is in the list of changed fields
+func isChanged(changed []string, field string) bool {
+	for _, name := range changed {
+		if name == field {
+			return true
+		}
+	}
+	return false
+}
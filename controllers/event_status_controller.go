@@ -2,9 +2,11 @@ package controllers
 
 import (
 	"context"
+	"sort"
 	"time"
 	"tools-backend/database"
 	"tools-backend/models"
+	"tools-backend/policies"
 	"tools-backend/utils"
 
 	"github.com/gin-gonic/gin"
@@ -37,21 +39,8 @@ func (esc *EventStatusController) CreateOrUpdateEventStatus(c *gin.Context) {
 		return
 	}
 
-	userIDInterface, exists := c.Get("user_id")
-	if !exists {
-		utils.ErrorResponse(c, 401, "User ID not found in token")
-		return
-	}
-
-	userID, ok := userIDInterface.(string)
+	actor, ok := currentActor(c)
 	if !ok {
-		utils.ErrorResponse(c, 401, "Invalid user ID format")
-		return
-	}
-
-	userObjectID, err := primitive.ObjectIDFromHex(userID)
-	if err != nil {
-		utils.ErrorResponse(c, 400, "Invalid user ID")
 		return
 	}
 
@@ -59,7 +48,7 @@ func (esc *EventStatusController) CreateOrUpdateEventStatus(c *gin.Context) {
 	eventCollection := database.GetCollection("events")
 	var event models.Event
 
-	err = eventCollection.FindOne(context.TODO(), bson.M{"_id": eventObjectID}).Decode(&event)
+	err = eventCollection.FindOne(context.TODO(), notDeleted(bson.M{"_id": eventObjectID})).Decode(&event)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			utils.ErrorResponse(c, 404, "Event not found")
@@ -70,73 +59,160 @@ func (esc *EventStatusController) CreateOrUpdateEventStatus(c *gin.Context) {
 	}
 
 	// Check if user is a participant (organizer or attendee)
-	isParticipant := false
-	for _, p := range event.Participants {
-		if p.UserID == userObjectID {
-			isParticipant = true
-			break
-		}
+	if !policies.CanRespond(actor, &event) {
+		utils.ErrorResponse(c, 403, "User is not invited to this event")
+		return
+	}
+	if !checkNotCancelled(c, &event) {
+		return
 	}
 
-	if !isParticipant {
-		utils.ErrorResponse(c, 403, "User is not invited to this event")
+	// Answers can be given for a single occurrence of a recurring event
+	if req.OccurrenceDate != "" && !checkOccurrence(c, &event, req.OccurrenceDate) {
+		return
+	}
+
+	// Seats of recurring events are counted per occurrence
+	if req.Status == models.StatusGoing && event.Capacity > 0 && event.Recurrence != nil && req.OccurrenceDate == "" {
+		utils.ErrorResponse(c, 400, "occurrence_date is required to take a seat at a recurring event")
 		return
 	}
 
-	// Check if EventStatus already exists
+	var historyDetails map[string]interface{}
+	if req.OccurrenceDate != "" {
+		historyDetails = map[string]interface{}{"occurrence_date": req.OccurrenceDate}
+	}
+
+	// Concurrent answers of the same user race between reading and saving: the unique index and the
+	// status pinned in the update make the loser give back its seat and start over
 	eventStatusCollection := database.GetCollection("event_statuses")
-	var existingEventStatus models.EventStatus
+	for attempt := 0; attempt < 3; attempt++ {
+		// Check if EventStatus already exists
+		var existingEventStatus models.EventStatus
+		err = eventStatusCollection.FindOne(context.TODO(), bson.M{
+			"event_id":        eventObjectID,
+			"user_id":         actor.UserID,
+			"occurrence_date": occurrenceFilter(req.OccurrenceDate),
+		}).Decode(&existingEventStatus)
+		if err != nil && err != mongo.ErrNoDocuments {
+			utils.ErrorResponse(c, 500, "Failed to check event status")
+			return
+		}
+		exists := err == nil
+		heldSeat := exists && existingEventStatus.Status == models.StatusGoing
+
+		// "going" needs a free seat, otherwise the attendee joins the waitlist (keeping their place if already on it)
+		now := time.Now()
+		status := req.Status
+		tookSeat := false
+		var waitlistedAt *time.Time
+		if status == models.StatusGoing && !heldSeat {
+			seated, err := takeSeat(&event, req.OccurrenceDate)
+			if err != nil {
+				utils.ErrorResponse(c, 500, "Failed to reserve a seat")
+				return
+			}
+			tookSeat = seated
+			if !seated {
+				status = models.StatusWaitlisted
+				waitlistedAt = &now
+				if exists && existingEventStatus.WaitlistedAt != nil {
+					waitlistedAt = existingEventStatus.WaitlistedAt
+				}
+			}
+		}
 
-	err = eventStatusCollection.FindOne(context.TODO(), bson.M{
-		"event_id": eventObjectID,
-		"user_id":  userObjectID,
-	}).Decode(&existingEventStatus)
-
-	if err == nil {
-		// EventStatus exists, update it
-		_, err = eventStatusCollection.UpdateOne(
-			context.TODO(),
-			bson.M{"_id": existingEventStatus.ID},
-			bson.M{"$set": bson.M{
-				"status":     req.Status,
-				"updated_at": time.Now(),
-			}},
-		)
+		message := "Event status updated successfully"
+		if status == models.StatusWaitlisted {
+			message = "The event is full, you have been added to the waitlist"
+		}
 
-		if err != nil {
-			utils.ErrorResponse(c, 500, "Failed to update event status")
+		if exists {
+			// EventStatus exists, update it
+			update := bson.M{"$set": bson.M{
+				"status":     status,
+				"updated_at": now,
+			}}
+			if waitlistedAt != nil {
+				update["$set"].(bson.M)["waitlisted_at"] = waitlistedAt
+			} else {
+				update["$unset"] = bson.M{"waitlisted_at": ""}
+			}
+
+			result, err := eventStatusCollection.UpdateOne(
+				context.TODO(),
+				bson.M{"_id": existingEventStatus.ID, "status": existingEventStatus.Status},
+				update,
+			)
+
+			if err != nil {
+				if tookSeat {
+					releaseSeat(&event, req.OccurrenceDate)
+				}
+				utils.ErrorResponse(c, 500, "Failed to update event status")
+				return
+			}
+			if result.MatchedCount == 0 {
+				if tookSeat {
+					releaseSeat(&event, req.OccurrenceDate)
+				}
+				continue
+			}
+			recordStatusChange(c, &event, existingEventStatus.Status, status, historyDetails)
+
+			// Giving up a seat hands it to the earliest waitlisted attendee
+			if heldSeat && status != models.StatusGoing {
+				releaseSeat(&event, req.OccurrenceDate)
+				promoteWaitlisted(&event, req.OccurrenceDate)
+			}
+
+			existingEventStatus.Status = status
+			existingEventStatus.WaitlistedAt = waitlistedAt
+			existingEventStatus.UpdatedAt = now
+			utils.SuccessResponse(c, 200, message, existingEventStatus.ToResponse())
 			return
 		}
 
-		existingEventStatus.Status = req.Status
-		existingEventStatus.UpdatedAt = time.Now()
-		utils.SuccessResponse(c, 200, "Event status updated successfully", existingEventStatus.ToResponse())
-	} else if err == mongo.ErrNoDocuments {
 		// Create new EventStatus
 		newEventStatus := models.EventStatus{
-			EventID:   eventObjectID,
-			UserID:    userObjectID,
-			Status:    req.Status,
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
+			EventID:        eventObjectID,
+			UserID:         actor.UserID,
+			OccurrenceDate: req.OccurrenceDate,
+			Status:         status,
+			WaitlistedAt:   waitlistedAt,
+			CreatedAt:      now,
+			UpdatedAt:      now,
 		}
 
 		result, err := eventStatusCollection.InsertOne(context.TODO(), newEventStatus)
 		if err != nil {
+			if tookSeat {
+				releaseSeat(&event, req.OccurrenceDate)
+			}
+			if mongo.IsDuplicateKeyError(err) {
+				continue
+			}
 			utils.ErrorResponse(c, 500, "Failed to create event status")
 			return
 		}
+		recordStatusChange(c, &event, "", status, historyDetails)
 
+		if status != models.StatusWaitlisted {
+			message = "Event status created successfully"
+		}
 		newEventStatus.ID = result.InsertedID.(primitive.ObjectID)
-		utils.SuccessResponse(c, 201, "Event status created successfully", newEventStatus.ToResponse())
-	} else {
-		utils.ErrorResponse(c, 500, "Failed to check event status")
+		utils.SuccessResponse(c, 201, message, newEventStatus.ToResponse())
+		return
 	}
+
+	utils.ErrorResponse(c, 409, "Your answer was changed at the same time, please try again")
 }
 
 // GetEventAttendees returns all attendees and their event statuses for an event (organizer only)
+// For recurring events ?occurrence_date= returns the answers for one occurrence
 func (esc *EventStatusController) GetEventAttendees(c *gin.Context) {
 	eventID := c.Param("id")
+	occurrenceDate := c.Query("occurrence_date")
 
 	eventObjectID, err := primitive.ObjectIDFromHex(eventID)
 	if err != nil {
@@ -144,21 +220,8 @@ func (esc *EventStatusController) GetEventAttendees(c *gin.Context) {
 		return
 	}
 
-	userIDInterface, exists := c.Get("user_id")
-	if !exists {
-		utils.ErrorResponse(c, 401, "User ID not found in token")
-		return
-	}
-
-	userID, ok := userIDInterface.(string)
+	actor, ok := currentActor(c)
 	if !ok {
-		utils.ErrorResponse(c, 401, "Invalid user ID format")
-		return
-	}
-
-	userObjectID, err := primitive.ObjectIDFromHex(userID)
-	if err != nil {
-		utils.ErrorResponse(c, 400, "Invalid user ID")
 		return
 	}
 
@@ -166,7 +229,7 @@ func (esc *EventStatusController) GetEventAttendees(c *gin.Context) {
 	eventCollection := database.GetCollection("events")
 	var event models.Event
 
-	err = eventCollection.FindOne(context.TODO(), bson.M{"_id": eventObjectID}).Decode(&event)
+	err = eventCollection.FindOne(context.TODO(), notDeleted(bson.M{"_id": eventObjectID})).Decode(&event)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			utils.ErrorResponse(c, 404, "Event not found")
@@ -176,39 +239,21 @@ func (esc *EventStatusController) GetEventAttendees(c *gin.Context) {
 		return
 	}
 
-	// Check if user is organizer
-	isOrganizer := false
-	for _, p := range event.Participants {
-		if p.UserID == userObjectID && p.Role == models.RoleOrganizer {
-			isOrganizer = true
-			break
-		}
-	}
-
-	if !isOrganizer {
+	// Check if user may do this (organizer, or platform staff)
+	if !policies.CanViewAttendees(actor, &event) {
 		utils.ErrorResponse(c, 403, "Only event organizers can view attendees")
 		return
 	}
 
-	// Get all event statuses for this event
-	eventStatusCollection := database.GetCollection("event_statuses")
-	cursor, err := eventStatusCollection.Find(context.TODO(), bson.M{"event_id": eventObjectID})
-	if err != nil {
-		utils.ErrorResponse(c, 500, "Failed to fetch attendees")
-		return
-	}
-	defer cursor.Close(context.TODO())
-
-	var eventStatusesList []models.EventStatus
-	if err = cursor.All(context.TODO(), &eventStatusesList); err != nil {
-		utils.ErrorResponse(c, 500, "Failed to process attendees")
+	if occurrenceDate != "" && !checkOccurrence(c, &event, occurrenceDate) {
 		return
 	}
 
 	// Map UserID -> EventStatus
-	statusMap := make(map[primitive.ObjectID]models.EventStatus)
-	for _, es := range eventStatusesList {
-		statusMap[es.UserID] = es
+	statusMap, err := findEffectiveStatuses(bson.M{"event_id": eventObjectID}, occurrenceDate)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to fetch attendees")
+		return
 	}
 
 	// Collect all participant UserIDs (excluding organizer if desired, but usually organizer is also a participant)
@@ -248,6 +293,7 @@ func (esc *EventStatusController) GetEventAttendees(c *gin.Context) {
 		"going":       0,
 		"maybe":       0,
 		"not_going":   0,
+		"waitlisted":  0,
 		"no_response": 0,
 	}
 
@@ -266,6 +312,7 @@ func (esc *EventStatusController) GetEventAttendees(c *gin.Context) {
 
 		if status, hasStatus := statusMap[uid]; hasStatus {
 			detail.Status = status.Status
+			detail.WaitlistedAt = status.WaitlistedAt
 			detail.UpdatedAt = status.UpdatedAt
 
 			// Update counts
@@ -276,6 +323,8 @@ func (esc *EventStatusController) GetEventAttendees(c *gin.Context) {
 				counts["maybe"] = counts["maybe"].(int) + 1
 			case models.StatusNotGoing:
 				counts["not_going"] = counts["not_going"].(int) + 1
+			case models.StatusWaitlisted:
+				counts["waitlisted"] = counts["waitlisted"].(int) + 1
 			}
 		} else {
 			counts["no_response"] = counts["no_response"].(int) + 1
@@ -286,13 +335,18 @@ func (esc *EventStatusController) GetEventAttendees(c *gin.Context) {
 
 	counts["total"] = len(attendeesDetails)
 	counts["attendees"] = attendeesDetails
+	if event.Capacity > 0 {
+		counts["capacity"] = event.Capacity
+	}
 
 	utils.SuccessResponse(c, 200, "Attendees retrieved successfully", counts)
 }
 
 // GetUserEventStatus returns the event status of the current user for an event
+// (for one occurrence with ?occurrence_date=, falling back to the answer for the whole event)
 func (esc *EventStatusController) GetUserEventStatus(c *gin.Context) {
 	eventID := c.Param("id")
+	occurrenceDate := c.Query("occurrence_date")
 
 	eventObjectID, err := primitive.ObjectIDFromHex(eventID)
 	if err != nil {
@@ -318,43 +372,61 @@ func (esc *EventStatusController) GetUserEventStatus(c *gin.Context) {
 		return
 	}
 
-	eventStatusCollection := database.GetCollection("event_statuses")
-	var eventStatus models.EventStatus
+	if occurrenceDate != "" {
+		if _, err := time.Parse("2006-01-02", occurrenceDate); err != nil {
+			utils.ErrorResponse(c, 400, "Invalid occurrence_date. Use YYYY-MM-DD")
+			return
+		}
+	}
 
-	err = eventStatusCollection.FindOne(context.TODO(), bson.M{
+	// Answers to deleted events are kept for a restore but no longer shown
+	var event models.Event
+	err = database.GetCollection("events").FindOne(context.TODO(), notDeleted(bson.M{"_id": eventObjectID})).Decode(&event)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			utils.ErrorResponse(c, 404, "Event not found")
+		} else {
+			utils.ErrorResponse(c, 500, "Failed to fetch event")
+		}
+		return
+	}
+
+	statusMap, err := findEffectiveStatuses(bson.M{
 		"event_id": eventObjectID,
 		"user_id":  userObjectID,
-	}).Decode(&eventStatus)
+	}, occurrenceDate)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to fetch event status")
+		return
+	}
 
-	if err == mongo.ErrNoDocuments {
+	eventStatus, exists := statusMap[userObjectID]
+	if !exists {
 		utils.SuccessResponse(c, 200, "No event status found", gin.H{
 			"status": "no_response",
 		})
 		return
 	}
 
-	if err != nil {
-		utils.ErrorResponse(c, 500, "Failed to fetch event status")
-		return
-	}
-
 	utils.SuccessResponse(c, 200, "Event status retrieved successfully", eventStatus.ToResponse())
 }
 
 // GetAttendeesByStatus returns attendees filtered by status for an event (organizer only)
 func (esc *EventStatusController) GetAttendeesByStatus(c *gin.Context) {
 	eventID := c.Param("id")
-	status := c.Query("status") // Query parameter: going, maybe, not_going
+	status := c.Query("status")                  // Query parameter: going, maybe, not_going
+	occurrenceDate := c.Query("occurrence_date") // Optional: one occurrence of a recurring event
 
 	// Validate status
 	validStatuses := map[string]bool{
-		"going":     true,
-		"maybe":     true,
-		"not_going": true,
+		"going":      true,
+		"maybe":      true,
+		"not_going":  true,
+		"waitlisted": true,
 	}
 
 	if status != "" && !validStatuses[status] {
-		utils.ErrorResponse(c, 400, "Invalid status. Must be: going, maybe, or not_going")
+		utils.ErrorResponse(c, 400, "Invalid status. Must be: going, maybe, not_going, or waitlisted")
 		return
 	}
 
@@ -364,21 +436,8 @@ func (esc *EventStatusController) GetAttendeesByStatus(c *gin.Context) {
 		return
 	}
 
-	userIDInterface, exists := c.Get("user_id")
-	if !exists {
-		utils.ErrorResponse(c, 401, "User ID not found in token")
-		return
-	}
-
-	userID, ok := userIDInterface.(string)
+	actor, ok := currentActor(c)
 	if !ok {
-		utils.ErrorResponse(c, 401, "Invalid user ID format")
-		return
-	}
-
-	userObjectID, err := primitive.ObjectIDFromHex(userID)
-	if err != nil {
-		utils.ErrorResponse(c, 400, "Invalid user ID")
 		return
 	}
 
@@ -386,7 +445,7 @@ func (esc *EventStatusController) GetAttendeesByStatus(c *gin.Context) {
 	eventCollection := database.GetCollection("events")
 	var event models.Event
 
-	err = eventCollection.FindOne(context.TODO(), bson.M{"_id": eventObjectID}).Decode(&event)
+	err = eventCollection.FindOne(context.TODO(), notDeleted(bson.M{"_id": eventObjectID})).Decode(&event)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			utils.ErrorResponse(c, 404, "Event not found")
@@ -396,46 +455,41 @@ func (esc *EventStatusController) GetAttendeesByStatus(c *gin.Context) {
 		return
 	}
 
-	// Check if user is organizer
-	isOrganizer := false
-	for _, p := range event.Participants {
-		if p.UserID == userObjectID && p.Role == models.RoleOrganizer {
-			isOrganizer = true
-			break
-		}
-	}
-
-	if !isOrganizer {
+	// Check if user may do this (organizer, or platform staff)
+	if !policies.CanViewAttendees(actor, &event) {
 		utils.ErrorResponse(c, 403, "Only event organizers can view attendees")
 		return
 	}
 
-	// Build filter
-	filter := bson.M{"event_id": eventObjectID}
-	if status != "" {
-		filter["status"] = models.EventStatusValue(status)
+	if occurrenceDate != "" && !checkOccurrence(c, &event, occurrenceDate) {
+		return
 	}
 
-	eventStatusCollection := database.GetCollection("event_statuses")
-	cursor, err := eventStatusCollection.Find(context.TODO(), filter)
+	// The status is filtered after picking each user's answer, as an answer for the
+	// occurrence replaces the answer for the whole event
+	effectiveStatuses, err := findEffectiveStatuses(bson.M{"event_id": eventObjectID}, occurrenceDate)
 	if err != nil {
 		utils.ErrorResponse(c, 500, "Failed to fetch attendees")
 		return
 	}
-	defer cursor.Close(context.TODO())
-
-	var eventStatusesList []models.EventStatus
-	if err = cursor.All(context.TODO(), &eventStatusesList); err != nil {
-		utils.ErrorResponse(c, 500, "Failed to process attendees")
-		return
-	}
 
 	// Map UserID -> EventStatus
 	statusMap := make(map[primitive.ObjectID]models.EventStatus)
 	var userIDs []primitive.ObjectID
-	for _, es := range eventStatusesList {
-		statusMap[es.UserID] = es
-		userIDs = append(userIDs, es.UserID)
+	for userID, es := range effectiveStatuses {
+		if status != "" && es.Status != models.EventStatusValue(status) {
+			continue
+		}
+		statusMap[userID] = es
+		userIDs = append(userIDs, userID)
+	}
+
+	// The waitlist is listed in the order seats are given out
+	if status == string(models.StatusWaitlisted) {
+		sort.Slice(userIDs, func(i, j int) bool {
+			a, b := statusMap[userIDs[i]].WaitlistedAt, statusMap[userIDs[j]].WaitlistedAt
+			return a != nil && (b == nil || a.Before(*b))
+		})
 	}
 
 	if len(userIDs) == 0 {
@@ -474,13 +528,44 @@ func (esc *EventStatusController) GetAttendeesByStatus(c *gin.Context) {
 
 		es := statusMap[uid]
 		attendeesDetails = append(attendeesDetails, models.EventAttendeeDetail{
-			UserID:    uid,
-			Name:      user.Name,
-			Email:     user.Email,
-			Status:    es.Status,
-			UpdatedAt: es.UpdatedAt,
+			UserID:       uid,
+			Name:         user.Name,
+			Email:        user.Email,
+			Status:       es.Status,
+			WaitlistedAt: es.WaitlistedAt,
+			UpdatedAt:    es.UpdatedAt,
 		})
 	}
 
 	utils.SuccessResponse(c, 200, "Attendees retrieved successfully", attendeesDetails)
 }
+
+// findEffectiveStatuses returns each user's answer matching the filter: for an occurrence the answer
+// given for it, falling back to the answer for the whole event; without a date only whole-event answers
+func findEffectiveStatuses(filter bson.M, occurrenceDate string) (map[primitive.ObjectID]models.EventStatus, error) {
+	if occurrenceDate == "" {
+		filter["occurrence_date"] = nil
+	} else {
+		filter["occurrence_date"] = bson.M{"$in": bson.A{nil, occurrenceDate}}
+	}
+
+	cursor, err := database.GetCollection("event_statuses").Find(context.TODO(), filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(context.TODO())
+
+	var statuses []models.EventStatus
+	if err = cursor.All(context.TODO(), &statuses); err != nil {
+		return nil, err
+	}
+
+	statusMap := make(map[primitive.ObjectID]models.EventStatus)
+	for _, es := range statuses {
+		if _, exists := statusMap[es.UserID]; exists && es.OccurrenceDate == "" {
+			continue
+		}
+		statusMap[es.UserID] = es
+	}
+	return statusMap, nil
+}
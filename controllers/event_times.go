@@ -0,0 +1,151 @@
+package controllers
+
+import (
+	"time"
+	"tools-backend/config"
+	"tools-backend/models"
+	"tools-backend/recurrence"
+	"tools-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// checkEventTimes validates the timezone and duration of an event, writing an error response on failure
+func checkEventTimes(c *gin.Context, startAt, endAt time.Time, timezone string) bool {
+	if _, err := utils.LoadTimezone(timezone); err != nil {
+		utils.ErrorResponse(c, 400, "Invalid timezone. Use an IANA name such as Europe/Paris")
+		return false
+	}
+	if !endAt.After(startAt) {
+		utils.ErrorResponse(c, 400, "end_at must be after start_at")
+		return false
+	}
+	if maxDuration := config.GetEventMaxDuration(); endAt.Sub(startAt) > maxDuration {
+		utils.ErrorResponse(c, 400, "An event cannot last longer than "+maxDuration.String())
+		return false
+	}
+	return true
+}
+
+// readEventTimes applies the start_at, end_at and timezone of an update request to the times
+// being changed, adding them to the update document. Moving only the start keeps the duration.
+func readEventTimes(c *gin.Context, req models.UpdateEventRequest, startAt, endAt time.Time, timezone string, changes bson.M) bool {
+	if req.StartAt == nil && req.EndAt == nil && req.Timezone == "" {
+		return true
+	}
+
+	newStartAt, newEndAt := startAt, endAt
+	if req.StartAt != nil {
+		newStartAt = req.StartAt.UTC()
+		newEndAt = newStartAt.Add(endAt.Sub(startAt))
+	}
+	if req.EndAt != nil {
+		newEndAt = req.EndAt.UTC()
+	}
+	if req.Timezone != "" {
+		timezone = req.Timezone
+	}
+
+	if !checkEventTimes(c, newStartAt, newEndAt, timezone) {
+		return false
+	}
+	if req.StartAt != nil && newStartAt.Before(time.Now()) {
+		utils.ErrorResponse(c, 400, "Event cannot start in the past")
+		return false
+	}
+
+	changes["start_at"] = newStartAt
+	changes["end_at"] = newEndAt
+	if req.Timezone != "" {
+		changes["timezone"] = req.Timezone
+	}
+	return true
+}
+
+// applyEventChanges applies an update document built by UpdateEvent to an event
+func applyEventChanges(event *models.Event, changes bson.M) {
+	if value, ok := changes["title"].(string); ok {
+		event.Title = value
+	}
+	if value, ok := changes["description"].(string); ok {
+		event.Description = value
+	}
+	if value, ok := changes["location"].(string); ok {
+		event.Location = value
+	}
+	if value, ok := changes["capacity"].(int); ok {
+		event.Capacity = value
+	}
+	if value, ok := changes["visibility"].(models.EventVisibility); ok {
+		event.Visibility = value
+	}
+	if value, ok := changes["start_at"].(time.Time); ok {
+		event.StartAt = value
+	}
+	if value, ok := changes["end_at"].(time.Time); ok {
+		event.EndAt = value
+	}
+	if value, ok := changes["timezone"].(string); ok {
+		event.Timezone = value
+	}
+}
+
+// viewerLocation returns the timezone picked by the ViewerTimezone middleware, or nil to
+// show every event in its own timezone
+func viewerLocation(c *gin.Context) *time.Location {
+	if loc, ok := c.Get("viewer_location"); ok {
+		return loc.(*time.Location)
+	}
+	return nil
+}
+
+// windowLocation returns the timezone date filters (?from=, ?start_date=, ...) are read in
+func windowLocation(c *gin.Context) *time.Location {
+	if loc := viewerLocation(c); loc != nil {
+		return loc
+	}
+	if loc, err := utils.LoadTimezone(config.GetDefaultTimezone()); err == nil {
+		return loc
+	}
+	return time.UTC
+}
+
+// localizeEvent renders the event times in the viewer's timezone
+func localizeEvent(c *gin.Context, resp models.EventResponse) models.EventResponse {
+	return resp.InZone(viewerLocation(c))
+}
+
+// localizeEvents renders the times of every event in the viewer's timezone
+func localizeEvents(c *gin.Context, responses []models.EventResponse) []models.EventResponse {
+	loc := viewerLocation(c)
+	for i := range responses {
+		responses[i] = responses[i].InZone(loc)
+	}
+	return responses
+}
+
+// occurrenceTimes returns when an occurrence starts and ends: at the series' local start time
+// on that date, so occurrences keep their wall-clock time across daylight saving changes
+func occurrenceTimes(event *models.Event, occurrenceDate string) (startAt, endAt time.Time) {
+	zone := event.Zone()
+	local := event.StartAt.In(zone)
+
+	date, err := recurrence.ParseDate(occurrenceDate)
+	if err != nil {
+		return event.StartAt, event.EndAt
+	}
+
+	startAt = time.Date(date.Year(), date.Month(), date.Day(), local.Hour(), local.Minute(), local.Second(), 0, zone).UTC()
+	return startAt, startAt.Add(event.Duration())
+}
+
+// parseLocalDate reads a YYYY-MM-DD date as midnight in the given timezone
+func parseLocalDate(value string, loc *time.Location) (time.Time, error) {
+	return time.ParseInLocation(recurrence.DateLayout, value, loc)
+}
+
+// dateOf returns the calendar date of a time as used by the recurrence package
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,83 @@
+package controllers
+
+import (
+	"fmt"
+	"strings"
+	"tools-backend/config"
+	"tools-backend/models"
+	"tools-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// eventETag returns the entity tag of the version of an event
+func eventETag(event *models.Event) string {
+	return fmt.Sprintf("\"%d\"", event.Version)
+}
+
+// checkIfMatch makes a change conditional on the If-Match header: a header naming another
+// version answers 412, a missing one 428 unless EVENT_REQUIRE_IF_MATCH is off (writes the response)
+func checkIfMatch(c *gin.Context, event *models.Event) bool {
+	header := c.GetHeader("If-Match")
+	if header == "" {
+		if config.GetEventRequireIfMatch() {
+			utils.ErrorResponse(c, 428, "The If-Match header is required, send the ETag of the event")
+			return false
+		}
+		return true
+	}
+
+	if !etagMatches(header, eventETag(event)) {
+		c.Header("ETag", eventETag(event))
+		versionConflict(c)
+		return false
+	}
+	return true
+}
+
+// notModified answers 304 when the If-None-Match header names the current version of the event;
+// the ETag header is set either way
+func notModified(c *gin.Context, event *models.Event) bool {
+	etag := eventETag(event)
+	c.Header("ETag", etag)
+
+	if header := c.GetHeader("If-None-Match"); header != "" && etagMatches(header, etag) {
+		c.Status(304)
+		return true
+	}
+	return false
+}
+
+// versionConflict answers 412 when the event changed since the client (or the handler) read it
+func versionConflict(c *gin.Context) {
+	utils.ErrorResponse(c, 412, "The event was changed by someone else, reload it and try again")
+}
+
+// etagMatches reports whether a list of entity tags (If-Match, If-None-Match) contains the tag
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// pinVersion makes an update only apply to the version of the event that was read
+// (events stored before versions existed have none)
+func pinVersion(filter bson.M, version int) bson.M {
+	if version == 0 {
+		filter["version"] = bson.M{"$exists": false}
+	} else {
+		filter["version"] = version
+	}
+	return filter
+}
+
+// bumpVersion adds the version increment to an update of an event, so every change gets a new ETag
+func bumpVersion(update bson.M) bson.M {
+	update["$inc"] = bson.M{"version": 1}
+	return update
+}
@@ -0,0 +1,103 @@
+package controllers
+
+import (
+	"context"
+	"strconv"
+	"tools-backend/database"
+	"tools-backend/models"
+	"tools-backend/policies"
+	"tools-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// loadCurrentUser fetches the authenticated user, writing an error response on failure
+func loadCurrentUser(c *gin.Context) (*models.User, bool) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		utils.ErrorResponse(c, 401, "User ID not found in token")
+		return nil, false
+	}
+
+	userID, ok := userIDInterface.(string)
+	if !ok {
+		utils.ErrorResponse(c, 401, "Invalid user ID format")
+		return nil, false
+	}
+
+	userObjectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		utils.ErrorResponse(c, 400, "Invalid user ID")
+		return nil, false
+	}
+
+	var user models.User
+	err = database.GetCollection("users").FindOne(context.TODO(), bson.M{"_id": userObjectID}).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			utils.ErrorResponse(c, 404, "User not found")
+		} else {
+			utils.ErrorResponse(c, 500, "Database error")
+		}
+		return nil, false
+	}
+
+	return &user, true
+}
+
+// currentActor builds the policy actor for the authenticated user, writing an error response on failure
+func currentActor(c *gin.Context) (policies.Actor, bool) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		utils.ErrorResponse(c, 401, "User ID not found in token")
+		return policies.Actor{}, false
+	}
+
+	userID, ok := userIDInterface.(string)
+	if !ok {
+		utils.ErrorResponse(c, 401, "Invalid user ID format")
+		return policies.Actor{}, false
+	}
+
+	userObjectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		utils.ErrorResponse(c, 400, "Invalid user ID")
+		return policies.Actor{}, false
+	}
+
+	return policies.Actor{
+		UserID: userObjectID,
+		Role:   models.PlatformRole(c.GetString("user_role")),
+	}, true
+}
+
+// parsePagination reads ?page= and ?limit= (1-based page, limit capped at maxLimit)
+func parsePagination(c *gin.Context, defaultLimit, maxLimit int64) (page, limit int64) {
+	page, err := strconv.ParseInt(c.DefaultQuery("page", "1"), 10, 64)
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit, err = strconv.ParseInt(c.DefaultQuery("limit", strconv.FormatInt(defaultLimit, 10)), 10, 64)
+	if err != nil || limit < 1 {
+		limit = defaultLimit
+	}
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+
+	return page, limit
+}
+
+// paginationMeta describes a page of results in list responses
+func paginationMeta(page, limit, total int64) gin.H {
+	return gin.H{
+		"page":        page,
+		"limit":       limit,
+		"total":       total,
+		"total_pages": (total + limit - 1) / limit,
+	}
+}
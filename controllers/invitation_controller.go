@@ -0,0 +1,448 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+	"tools-backend/config"
+	"tools-backend/database"
+	"tools-backend/mailer"
+	"tools-backend/models"
+	"tools-backend/policies"
+	"tools-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// InvitationController handles invitations of guests without an account
+type InvitationController struct{}
+
+// GetInvitation shows the event and the current answer of a guest (public, authorized by the link token)
+func (ic *InvitationController) GetInvitation(c *gin.Context) {
+	invitation, event, ok := invitationFromToken(c)
+	if !ok {
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Invitation retrieved successfully", gin.H{
+		"event":      localizeEvent(c, event.ToResponse()),
+		"invitation": invitation.ToResponse(),
+	})
+}
+
+// RespondToInvitation records a guest's answer (public, authorized by the link token).
+// Guests answer for the whole event; a "going" answer takes a seat like any other.
+func (ic *InvitationController) RespondToInvitation(c *gin.Context) {
+	var req models.GuestRSVPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, "Invalid request data")
+		return
+	}
+
+	// Validate request
+	if errors := utils.ValidateStruct(req); len(errors) > 0 {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	invitation, event, ok := invitationFromToken(c)
+	if !ok {
+		return
+	}
+	if !checkNotCancelled(c, event) {
+		return
+	}
+
+	wasGoing := invitation.Status == models.StatusGoing
+	going := req.Status == models.StatusGoing
+	if going && !wasGoing {
+		// Seats of recurring events are counted per occurrence, which guests cannot pick
+		if event.Recurrence != nil && event.Capacity > 0 {
+			utils.ErrorResponse(c, 400, "Please create an account to answer going to this recurring event")
+			return
+		}
+		seated, err := takeSeat(event, "")
+		if err != nil {
+			utils.ErrorResponse(c, 500, "Failed to reserve a seat")
+			return
+		}
+		if !seated {
+			utils.ErrorResponse(c, 409, "The event is full")
+			return
+		}
+	}
+
+	// The answer read above is pinned, so two answers sent at once cannot both take a seat
+	var previousStatus interface{}
+	if invitation.Status != "" {
+		previousStatus = invitation.Status
+	}
+
+	now := time.Now()
+	result, err := database.GetCollection("event_invitations").UpdateOne(
+		context.TODO(),
+		bson.M{"_id": invitation.ID, "status": previousStatus},
+		bson.M{"$set": bson.M{"status": req.Status, "responded_at": now, "updated_at": now}},
+	)
+	if err != nil || result.MatchedCount == 0 {
+		if going && !wasGoing {
+			releaseSeat(event, "")
+		}
+		if err != nil {
+			utils.ErrorResponse(c, 500, "Failed to save your answer")
+		} else {
+			utils.ErrorResponse(c, 409, "Your answer was changed at the same time, please try again")
+		}
+		return
+	}
+	recordStatusChange(c, event, invitation.Status, req.Status, map[string]interface{}{"guest_email": invitation.Email})
+
+	if wasGoing && !going {
+		releaseSeat(event, "")
+		promoteWaitlisted(event, "")
+	}
+
+	invitation.Status = req.Status
+	invitation.RespondedAt = &now
+	utils.SuccessResponse(c, 200, "Your answer has been saved", invitation.ToResponse())
+}
+
+// ListEventInvitations returns the pending guest invitations of an event (organizers only)
+func (ic *InvitationController) ListEventInvitations(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	event, ok := loadEvent(c)
+	if !ok {
+		return
+	}
+
+	if !policies.CanInvite(actor, event) {
+		utils.ErrorResponse(c, 403, "Only event organizers can view invitations")
+		return
+	}
+
+	cursor, err := database.GetCollection("event_invitations").Find(
+		context.TODO(),
+		bson.M{"event_id": event.ID},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}),
+	)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to fetch invitations")
+		return
+	}
+	defer cursor.Close(context.TODO())
+
+	var invitations []models.EventInvitation
+	if err = cursor.All(context.TODO(), &invitations); err != nil {
+		utils.ErrorResponse(c, 500, "Failed to decode invitations")
+		return
+	}
+
+	responses := make([]models.EventInvitationResponse, 0, len(invitations))
+	for _, invitation := range invitations {
+		responses = append(responses, invitation.ToResponse())
+	}
+
+	utils.SuccessResponse(c, 200, "Invitations retrieved successfully", responses)
+}
+
+// DeleteInvitation withdraws a pending guest invitation; its link stops working (organizers only)
+func (ic *InvitationController) DeleteInvitation(c *gin.Context) {
+	invitationID, err := primitive.ObjectIDFromHex(c.Param("invitationId"))
+	if err != nil {
+		utils.ErrorResponse(c, 400, "Invalid invitation ID")
+		return
+	}
+
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	event, ok := loadEvent(c)
+	if !ok {
+		return
+	}
+
+	if !policies.CanInvite(actor, event) {
+		utils.ErrorResponse(c, 403, "Only event organizers can withdraw invitations")
+		return
+	}
+
+	var invitation models.EventInvitation
+	err = database.GetCollection("event_invitations").FindOneAndDelete(
+		context.TODO(),
+		bson.M{"_id": invitationID, "event_id": event.ID},
+	).Decode(&invitation)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			utils.ErrorResponse(c, 404, "Invitation not found")
+		} else {
+			utils.ErrorResponse(c, 500, "Failed to withdraw invitation")
+		}
+		return
+	}
+
+	if invitation.Status == models.StatusGoing {
+		releaseSeat(event, "")
+		promoteWaitlisted(event, "")
+	}
+
+	utils.SuccessResponse(c, 200, "Invitation withdrawn successfully", nil)
+}
+
+// invitationFromToken loads the invitation and event of the :token route parameter
+// (writes the error response when it cannot)
+func invitationFromToken(c *gin.Context) (*models.EventInvitation, *models.Event, bool) {
+	claims, err := utils.ParseSignedToken(c.Param("token"), "event_invitation")
+	if err != nil {
+		utils.ErrorResponse(c, 400, "Invalid or expired invitation link")
+		return nil, nil, false
+	}
+
+	invitationIDString, _ := claims["invitation_id"].(string)
+	invitationID, err := primitive.ObjectIDFromHex(invitationIDString)
+	if err != nil {
+		utils.ErrorResponse(c, 400, "Invalid or expired invitation link")
+		return nil, nil, false
+	}
+
+	// Withdrawn invitations, and those already turned into an account, are gone
+	var invitation models.EventInvitation
+	err = database.GetCollection("event_invitations").FindOne(context.TODO(), bson.M{"_id": invitationID}).Decode(&invitation)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			utils.ErrorResponse(c, 404, "Invitation not found. If you created an account, please log in to answer")
+		} else {
+			utils.ErrorResponse(c, 500, "Failed to fetch invitation")
+		}
+		return nil, nil, false
+	}
+
+	var event models.Event
+	err = database.GetCollection("events").FindOne(context.TODO(), notDeleted(bson.M{"_id": invitation.EventID})).Decode(&event)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			utils.ErrorResponse(c, 404, "Event not found")
+		} else {
+			utils.ErrorResponse(c, 500, "Failed to fetch event")
+		}
+		return nil, nil, false
+	}
+
+	return &invitation, &event, true
+}
+
+// existingUserIDs returns the IDs among the given ones that belong to an account
+func existingUserIDs(ids []primitive.ObjectID) ([]primitive.ObjectID, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	cursor, err := database.GetCollection("users").Find(
+		context.TODO(),
+		bson.M{"_id": bson.M{"$in": ids}},
+		options.Find().SetProjection(bson.M{"_id": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(context.TODO())
+
+	var users []models.User
+	if err = cursor.All(context.TODO(), &users); err != nil {
+		return nil, err
+	}
+
+	found := make([]primitive.ObjectID, 0, len(users))
+	for _, user := range users {
+		found = append(found, user.ID)
+	}
+	return found, nil
+}
+
+// splitInviteEmails returns the accounts of the addresses that have one and the (lowercase)
+// addresses that do not
+func splitInviteEmails(emails []string) ([]primitive.ObjectID, []string, error) {
+	var guests []string
+	seen := map[string]bool{}
+	for _, email := range emails {
+		email = strings.ToLower(strings.TrimSpace(email))
+		if !seen[email] {
+			seen[email] = true
+			guests = append(guests, email)
+		}
+	}
+	if len(guests) == 0 {
+		return nil, nil, nil
+	}
+
+	// Case-insensitive, as addresses are stored the way users typed them
+	cursor, err := database.GetCollection("users").Find(
+		context.TODO(),
+		bson.M{"email": bson.M{"$in": guests}},
+		options.Find().
+			SetProjection(bson.M{"_id": 1, "email": 1}).
+			SetCollation(&options.Collation{Locale: "en", Strength: 2}),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer cursor.Close(context.TODO())
+
+	var users []models.User
+	if err = cursor.All(context.TODO(), &users); err != nil {
+		return nil, nil, err
+	}
+
+	var registered []primitive.ObjectID
+	for _, user := range users {
+		registered = append(registered, user.ID)
+		delete(seen, strings.ToLower(user.Email))
+	}
+
+	unregistered := guests[:0]
+	for _, email := range guests {
+		if seen[email] {
+			unregistered = append(unregistered, email)
+		}
+	}
+	return registered, unregistered, nil
+}
+
+// uniqueObjectIDs removes duplicates, keeping the order
+func uniqueObjectIDs(ids []primitive.ObjectID) []primitive.ObjectID {
+	seen := map[primitive.ObjectID]bool{}
+	unique := make([]primitive.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+// inviteGuest creates (or keeps) the pending invitation of an address and emails it a fresh link
+func inviteGuest(event *models.Event, email string, invitedBy primitive.ObjectID) error {
+	now := time.Now()
+	var invitation models.EventInvitation
+	err := database.GetCollection("event_invitations").FindOneAndUpdate(
+		context.TODO(),
+		bson.M{"event_id": event.ID, "email": email},
+		bson.M{
+			"$set":         bson.M{"updated_at": now},
+			"$setOnInsert": bson.M{"invited_by": invitedBy, "created_at": now},
+		},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&invitation)
+	if err != nil {
+		return err
+	}
+
+	// Sending is best effort, like the other emails: the organizer can invite again
+	if err := sendGuestInvitation(event, &invitation); err != nil {
+		log.Printf("Failed to send invitation email to %s: %v", email, err)
+	}
+	return nil
+}
+
+// sendGuestInvitation emails a signed RSVP link to an invited guest
+func sendGuestInvitation(event *models.Event, invitation *models.EventInvitation) error {
+	ttl := config.GetEventInvitationTTL()
+	token, err := utils.GenerateSignedToken("event_invitation", jwt.MapClaims{
+		"invitation_id": invitation.ID.Hex(),
+	}, ttl)
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/api/v1/invitations/%s", config.GetAppURL(), url.PathEscape(token))
+	return mailer.Send(mailer.Message{
+		To:      invitation.Email,
+		Subject: "You are invited: " + event.Title,
+		Body: fmt.Sprintf("Hello,\n\nYou are invited to \"%s\" on %s at %s.\n\n%s\n\nOpen the link below to see the event and answer, no account needed. It expires in %s. If you create an account with this address and confirm it, the invitation moves to it.\n\n%s",
+			event.Title, event.StartAt.In(event.Zone()).Format("Monday 2 January 2006, 15:04 MST"), event.Location, event.Description, ttl, link),
+	})
+}
+
+// claimInvitations turns the pending invitations of an address into participations of the account
+// that proved it owns the address (verified, confirmed or vouched for by an identity provider),
+// keeping the answers given as a guest (a "going" guest keeps their seat unless the account has
+// already answered, in which case that answer wins)
+func claimInvitations(c *gin.Context, user *models.User) {
+	collection := database.GetCollection("event_invitations")
+	cursor, err := collection.Find(context.TODO(), bson.M{"email": strings.ToLower(user.Email)})
+	if err != nil {
+		log.Printf("Failed to load the invitations of %s: %v", user.Email, err)
+		return
+	}
+	var invitations []models.EventInvitation
+	err = cursor.All(context.TODO(), &invitations)
+	cursor.Close(context.TODO())
+	if err != nil {
+		log.Printf("Failed to load the invitations of %s: %v", user.Email, err)
+		return
+	}
+
+	for _, invitation := range invitations {
+		var event models.Event
+		if err := database.GetCollection("events").FindOne(context.TODO(), bson.M{"_id": invitation.EventID}).Decode(&event); err != nil {
+			log.Printf("Failed to load event %s for the invitation of %s: %v", invitation.EventID.Hex(), user.Email, err)
+			continue
+		}
+
+		result, err := database.GetCollection("events").UpdateOne(
+			context.TODO(),
+			bson.M{"_id": invitation.EventID, "participants.user_id": bson.M{"$ne": user.ID}},
+			bumpVersion(bson.M{
+				"$push": bson.M{"participants": models.EventParticipant{UserID: user.ID, Role: models.RoleAttendee}},
+				"$set":  bson.M{"updated_at": time.Now()},
+			}),
+		)
+		if err != nil {
+			log.Printf("Failed to add %s to event %s: %v", user.Email, invitation.EventID.Hex(), err)
+			continue
+		}
+		if result.ModifiedCount > 0 {
+			if entry, ok := eventChangeEntry(models.HistoryJoined, &event, event.ID, map[string]interface{}{"invitation_id": invitation.ID}); ok {
+				entry.ActorID = &user.ID
+				addHistoryEntry(c, entry)
+			}
+		}
+
+		if invitation.Status != "" {
+			respondedAt := invitation.UpdatedAt
+			if invitation.RespondedAt != nil {
+				respondedAt = *invitation.RespondedAt
+			}
+			_, err := database.GetCollection("event_statuses").InsertOne(context.TODO(), models.EventStatus{
+				EventID:   invitation.EventID,
+				UserID:    user.ID,
+				Status:    invitation.Status,
+				CreatedAt: respondedAt,
+				UpdatedAt: respondedAt,
+			})
+			if mongo.IsDuplicateKeyError(err) && invitation.Status == models.StatusGoing {
+				// The account had already answered: the seat taken as a guest is given back
+				releaseSeat(&event, "")
+				promoteWaitlisted(&event, "")
+			} else if err != nil && !mongo.IsDuplicateKeyError(err) {
+				log.Printf("Failed to keep the answer of %s to event %s: %v", user.Email, invitation.EventID.Hex(), err)
+			}
+		}
+
+		collection.DeleteOne(context.TODO(), bson.M{"_id": invitation.ID})
+	}
+}
@@ -0,0 +1,275 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"tools-backend/config"
+	"tools-backend/database"
+	"tools-backend/models"
+	"tools-backend/policies"
+	"tools-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// inviteLinkPrefix marks invite link tokens so they are easy to tell apart from API keys
+const inviteLinkPrefix = "tbi_"
+
+// InviteLinkController handles shareable event invite links
+type InviteLinkController struct{}
+
+// CreateInviteLink creates an invite link for an event; the token is only returned once
+func (ilc *InviteLinkController) CreateInviteLink(c *gin.Context) {
+	var req models.CreateInviteLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, "Invalid request data")
+		return
+	}
+
+	// Validate request
+	if errors := utils.ValidateStruct(req); len(errors) > 0 {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	event, ok := loadEvent(c)
+	if !ok {
+		return
+	}
+
+	if !policies.CanInvite(actor, event) {
+		utils.ErrorResponse(c, 403, "Only event organizers can create invite links")
+		return
+	}
+	if !checkNotCancelled(c, event) {
+		return
+	}
+
+	secret, err := utils.GenerateRandomToken(24)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to generate invite link")
+		return
+	}
+	token := inviteLinkPrefix + secret
+
+	link := models.EventInviteLink{
+		EventID:   event.ID,
+		CreatedBy: actor.UserID,
+		Prefix:    token[:len(inviteLinkPrefix)+8],
+		TokenHash: utils.HashToken(token),
+		MaxUses:   req.MaxUses,
+		Domain:    strings.ToLower(req.Domain),
+		CreatedAt: time.Now(),
+	}
+	if req.ExpiresInHours > 0 {
+		expiresAt := time.Now().Add(time.Duration(req.ExpiresInHours) * time.Hour)
+		link.ExpiresAt = &expiresAt
+	}
+
+	result, err := database.GetCollection("event_invite_links").InsertOne(context.TODO(), link)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to create invite link")
+		return
+	}
+
+	link.ID = result.InsertedID.(primitive.ObjectID)
+	utils.SuccessResponse(c, 201, "Invite link created. Copy it now, it will not be shown again", gin.H{
+		"token":       token,
+		"url":         fmt.Sprintf("%s/api/v1/invite-links/%s/redeem", config.GetAppURL(), token),
+		"invite_link": link.ToResponse(),
+	})
+}
+
+// ListInviteLinks returns the invite links of an event, revoked and used up ones included
+func (ilc *InviteLinkController) ListInviteLinks(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	event, ok := loadEvent(c)
+	if !ok {
+		return
+	}
+
+	if !policies.CanInvite(actor, event) {
+		utils.ErrorResponse(c, 403, "Only event organizers can view invite links")
+		return
+	}
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cursor, err := database.GetCollection("event_invite_links").Find(context.TODO(), bson.M{"event_id": event.ID}, findOptions)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to fetch invite links")
+		return
+	}
+	defer cursor.Close(context.TODO())
+
+	var links []models.EventInviteLink
+	if err = cursor.All(context.TODO(), &links); err != nil {
+		utils.ErrorResponse(c, 500, "Failed to decode invite links")
+		return
+	}
+
+	responses := make([]models.EventInviteLinkResponse, 0, len(links))
+	for _, link := range links {
+		responses = append(responses, link.ToResponse())
+	}
+
+	utils.SuccessResponse(c, 200, "Invite links retrieved successfully", responses)
+}
+
+// RevokeInviteLink stops an invite link from being redeemed; people who already joined stay
+func (ilc *InviteLinkController) RevokeInviteLink(c *gin.Context) {
+	linkID, err := primitive.ObjectIDFromHex(c.Param("linkId"))
+	if err != nil {
+		utils.ErrorResponse(c, 400, "Invalid invite link ID")
+		return
+	}
+
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	event, ok := loadEvent(c)
+	if !ok {
+		return
+	}
+
+	if !policies.CanInvite(actor, event) {
+		utils.ErrorResponse(c, 403, "Only event organizers can revoke invite links")
+		return
+	}
+
+	result, err := database.GetCollection("event_invite_links").UpdateOne(
+		context.TODO(),
+		bson.M{"_id": linkID, "event_id": event.ID, "revoked_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revoked_at": time.Now()}},
+	)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to revoke invite link")
+		return
+	}
+	if result.MatchedCount == 0 {
+		utils.ErrorResponse(c, 404, "Invite link not found or already revoked")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Invite link revoked successfully", nil)
+}
+
+// RedeemInviteLink makes the current user an attendee of the link's event
+func (ilc *InviteLinkController) RedeemInviteLink(c *gin.Context) {
+	user, ok := loadCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	links := database.GetCollection("event_invite_links")
+	var link models.EventInviteLink
+	err := links.FindOne(context.TODO(), bson.M{"token_hash": utils.HashToken(c.Param("token"))}).Decode(&link)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			utils.ErrorResponse(c, 404, "Invite link not found")
+		} else {
+			utils.ErrorResponse(c, 500, "Failed to fetch invite link")
+		}
+		return
+	}
+
+	if link.RevokedAt != nil {
+		utils.ErrorResponse(c, 410, "This invite link has been revoked")
+		return
+	}
+	if link.ExpiresAt != nil && !time.Now().Before(*link.ExpiresAt) {
+		utils.ErrorResponse(c, 410, "This invite link has expired")
+		return
+	}
+	if link.Domain != "" {
+		if !strings.HasSuffix(strings.ToLower(user.Email), "@"+link.Domain) {
+			utils.ErrorResponse(c, 403, "This invite link is restricted to @"+link.Domain+" addresses")
+			return
+		}
+		// Anyone can register an address, so only a verified one proves membership of the domain
+		if user.VerifiedAt == nil {
+			utils.ErrorResponse(c, 403, "Please verify your email address before using this invite link")
+			return
+		}
+	}
+
+	var event models.Event
+	err = database.GetCollection("events").FindOne(context.TODO(), notDeleted(bson.M{"_id": link.EventID})).Decode(&event)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			utils.ErrorResponse(c, 404, "Event not found")
+		} else {
+			utils.ErrorResponse(c, 500, "Failed to fetch event")
+		}
+		return
+	}
+
+	// Opening the link again must not use it up
+	if findParticipant(&event, user.ID) != nil {
+		utils.SuccessResponse(c, 200, "You are already a participant of this event", localizeEvent(c, event.ToResponse()))
+		return
+	}
+	if !checkNotCancelled(c, &event) {
+		return
+	}
+
+	// Count the use atomically so concurrent redemptions cannot exceed max_uses
+	result, err := links.UpdateOne(
+		context.TODO(),
+		bson.M{
+			"_id":        link.ID,
+			"revoked_at": bson.M{"$exists": false},
+			"$or": bson.A{
+				bson.M{"max_uses": 0},
+				bson.M{"$expr": bson.M{"$lt": bson.A{"$uses", "$max_uses"}}},
+			},
+		},
+		bson.M{"$inc": bson.M{"uses": 1}},
+	)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to redeem invite link")
+		return
+	}
+	if result.MatchedCount == 0 {
+		utils.ErrorResponse(c, 410, "This invite link has reached its maximum number of uses")
+		return
+	}
+
+	participant := models.EventParticipant{UserID: user.ID, Role: models.RoleAttendee}
+	added, err := database.GetCollection("events").UpdateOne(
+		context.TODO(),
+		bson.M{"_id": event.ID, "participants.user_id": bson.M{"$ne": user.ID}},
+		bumpVersion(bson.M{"$push": bson.M{"participants": participant}, "$set": bson.M{"updated_at": time.Now()}}),
+	)
+	if err != nil || added.ModifiedCount == 0 {
+		// Not added (failed, or joined meanwhile): give the use back
+		links.UpdateOne(context.TODO(), bson.M{"_id": link.ID}, bson.M{"$inc": bson.M{"uses": -1}})
+		if err != nil {
+			utils.ErrorResponse(c, 500, "Failed to join event")
+		} else {
+			utils.SuccessResponse(c, 200, "You are already a participant of this event", nil)
+		}
+		return
+	}
+
+	recordEventChange(c, models.HistoryJoined, &event, event.ID, map[string]interface{}{"invite_link_id": link.ID})
+
+	event.Participants = append(event.Participants, participant)
+	utils.SuccessResponse(c, 200, "You joined the event", localizeEvent(c, event.ToResponse()))
+}
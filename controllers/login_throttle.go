@@ -0,0 +1,192 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+	"tools-backend/config"
+	"tools-backend/database"
+	"tools-backend/mailer"
+	"tools-backend/models"
+	"tools-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// maxLoginBackoff caps the progressive delay between failed attempts
+const maxLoginBackoff = 5 * time.Minute
+
+// accountThrottleKey and ipThrottleKey identify the login_attempts documents
+func accountThrottleKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipThrottleKey(ip string) string {
+	return "ip:" + ip
+}
+
+// checkLoginThrottle writes a 429 response and returns false when the account or IP
+// is locked or must still wait after its last failure
+func checkLoginThrottle(c *gin.Context, email string) bool {
+	collection := database.GetCollection("login_attempts")
+	now := time.Now()
+
+	cursor, err := collection.Find(context.TODO(), bson.M{
+		"key": bson.M{"$in": bson.A{accountThrottleKey(email), ipThrottleKey(c.ClientIP())}},
+	})
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Database error")
+		return false
+	}
+	defer cursor.Close(context.TODO())
+
+	var attempts []models.LoginAttempt
+	if err = cursor.All(context.TODO(), &attempts); err != nil {
+		utils.ErrorResponse(c, 500, "Database error")
+		return false
+	}
+
+	for _, attempt := range attempts {
+		if attempt.LockedUntil != nil && attempt.LockedUntil.After(now) {
+			writeThrottled(c, attempt.LockedUntil.Sub(now), "Too many failed login attempts, access is temporarily locked")
+			return false
+		}
+
+		if attempt.Failures == 0 || now.Sub(attempt.LastFailureAt) > config.GetLoginFailureWindow() {
+			continue
+		}
+
+		// Progressive backoff: base, 2x base, 4x base, ... after each failure
+		backoff := config.GetLoginBackoffBase() << (attempt.Failures - 1)
+		if backoff <= 0 || backoff > maxLoginBackoff {
+			backoff = maxLoginBackoff
+		}
+		if retryAt := attempt.LastFailureAt.Add(backoff); retryAt.After(now) {
+			writeThrottled(c, retryAt.Sub(now), "Too many failed login attempts, please slow down")
+			return false
+		}
+	}
+
+	return true
+}
+
+// recordLoginFailure counts a failed attempt for the account and the IP, locking them at the threshold
+func recordLoginFailure(c *gin.Context, email string) {
+	ip := c.ClientIP()
+
+	if locked := incrementLoginFailures(accountThrottleKey(email), config.GetLoginMaxAccountFailures()); locked {
+		var user models.User
+		err := database.GetCollection("users").FindOne(context.TODO(), bson.M{"email": email}).Decode(&user)
+
+		entry := models.AuditLog{
+			Action:    models.AuditAccountLocked,
+			IP:        ip,
+			UserAgent: c.Request.UserAgent(),
+			Details:   map[string]interface{}{"email": email, "locked_for": config.GetLoginLockoutDuration().String()},
+		}
+		if err == nil {
+			entry.TargetUserID = &user.ID
+			sendUnlockEmail(&user)
+		}
+		utils.RecordAudit(entry)
+	}
+
+	if locked := incrementLoginFailures(ipThrottleKey(ip), config.GetLoginMaxIPFailures()); locked {
+		utils.RecordAudit(models.AuditLog{
+			Action:    models.AuditIPLocked,
+			IP:        ip,
+			UserAgent: c.Request.UserAgent(),
+			Details:   map[string]interface{}{"locked_for": config.GetLoginLockoutDuration().String()},
+		})
+	}
+}
+
+// recordLoginSuccess clears the account counter (the IP counter is kept on purpose,
+// so one valid account cannot be used to reset an attacker's IP)
+func recordLoginSuccess(email string) {
+	database.GetCollection("login_attempts").DeleteOne(context.TODO(), bson.M{"key": accountThrottleKey(email)})
+}
+
+// incrementLoginFailures adds a failure to the key and returns true when this failure locked it
+func incrementLoginFailures(key string, maxFailures int) bool {
+	collection := database.GetCollection("login_attempts")
+	now := time.Now()
+
+	// Failures older than the window no longer count
+	collection.UpdateOne(
+		context.TODO(),
+		bson.M{"key": key, "last_failure_at": bson.M{"$lt": now.Add(-config.GetLoginFailureWindow())}},
+		bson.M{"$set": bson.M{"failures": 0}},
+	)
+
+	var attempt models.LoginAttempt
+	err := collection.FindOneAndUpdate(
+		context.TODO(),
+		bson.M{"key": key},
+		bson.M{"$inc": bson.M{"failures": 1}, "$set": bson.M{"last_failure_at": now}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&attempt)
+	if err != nil {
+		log.Printf("Failed to record login failure for %s: %v", key, err)
+		return false
+	}
+
+	if attempt.Failures < maxFailures {
+		return false
+	}
+
+	// Lock and start counting again; the filter makes sure only one instance performs the lock
+	result, err := collection.UpdateOne(
+		context.TODO(),
+		bson.M{"_id": attempt.ID, "failures": bson.M{"$gte": maxFailures}},
+		bson.M{"$set": bson.M{"failures": 0, "locked_until": now.Add(config.GetLoginLockoutDuration())}},
+	)
+	if err != nil {
+		log.Printf("Failed to lock %s: %v", key, err)
+		return false
+	}
+	return result.ModifiedCount == 1
+}
+
+// clearAccountLock removes the failure counter and lock of an account
+func clearAccountLock(email string) error {
+	_, err := database.GetCollection("login_attempts").DeleteOne(context.TODO(), bson.M{"key": accountThrottleKey(email)})
+	return err
+}
+
+// writeThrottled answers 429 with a Retry-After header
+func writeThrottled(c *gin.Context, wait time.Duration, message string) {
+	seconds := int(wait.Seconds()) + 1
+	c.Header("Retry-After", fmt.Sprint(seconds))
+	utils.ErrorResponse(c, 429, fmt.Sprintf("%s. Try again in %d seconds", message, seconds))
+}
+
+// sendUnlockEmail tells the owner about the lockout and gives them a link to unlock right away
+func sendUnlockEmail(user *models.User) {
+	ttl := config.GetLoginLockoutDuration()
+	token, err := utils.GenerateSignedToken("account_unlock", jwt.MapClaims{
+		"user_id": user.ID.Hex(),
+		"email":   user.Email,
+	}, ttl)
+	if err != nil {
+		log.Printf("Failed to create unlock token for %s: %v", user.Email, err)
+		return
+	}
+
+	link := fmt.Sprintf("%s/api/v1/account/unlock?token=%s", config.GetAppURL(), url.QueryEscape(token))
+	err = mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Your account has been temporarily locked",
+		Body: fmt.Sprintf("Hi %s,\n\nWe locked your account for %s after too many failed login attempts.\n\nIf this was you, unlock it now:\n\n%s\n\nIf it was not you, consider resetting your password.",
+			user.Name, ttl, link),
+	})
+	if err != nil {
+		log.Printf("Failed to send unlock email to %s: %v", user.Email, err)
+	}
+}
@@ -0,0 +1,642 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"time"
+	"tools-backend/database"
+	"tools-backend/models"
+	"tools-backend/recurrence"
+	"tools-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// defaultOccurrenceDays is how far ahead recurring events are expanded when no ?to= is given
+	defaultOccurrenceDays = 90
+	// maxOccurrenceDays limits the window a single request can expand
+	maxOccurrenceDays = 366
+)
+
+// recurrenceSet builds the occurrence set of a recurring event
+func recurrenceSet(event *models.Event) (*recurrence.Set, error) {
+	if event.Recurrence == nil {
+		return nil, errors.New("event does not repeat")
+	}
+
+	rule, err := recurrence.Parse(event.Recurrence.RRule)
+	if err != nil {
+		return nil, err
+	}
+	start, err := recurrence.ParseDate(event.LocalDate())
+	if err != nil {
+		return nil, err
+	}
+
+	set := &recurrence.Set{Start: start, Rule: rule}
+	if set.ExDates, err = parseDates(event.Recurrence.ExDates); err != nil {
+		return nil, err
+	}
+	if set.RDates, err = parseDates(event.Recurrence.RDates); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+// normalizeRecurrence checks a recurrence against the event's first local date and rewrites
+// its rule and dates in canonical form (callers answer "Invalid recurrence: " + err)
+func normalizeRecurrence(rec *models.EventRecurrence, startDate string) error {
+	rule, err := recurrence.Parse(rec.RRule)
+	if err != nil {
+		return err
+	}
+
+	start, err := recurrence.ParseDate(startDate)
+	if err != nil {
+		return errors.New("the event date must use YYYY-MM-DD")
+	}
+	if !rule.Until.IsZero() && rule.Until.Before(start) {
+		return errors.New("UNTIL cannot be before the event date")
+	}
+	if set := (recurrence.Set{Start: start, Rule: rule}); !set.StartMatches() {
+		return errors.New("the event date must be the first occurrence of the rule")
+	}
+
+	exDates, err := parseDates(rec.ExDates)
+	if err != nil {
+		return errors.New("exdates must use YYYY-MM-DD")
+	}
+	rDates, err := parseDates(rec.RDates)
+	if err != nil {
+		return errors.New("rdates must use YYYY-MM-DD")
+	}
+
+	rec.RRule = rule.String()
+	rec.ExDates = formatDates(exDates)
+	rec.RDates = formatDates(rDates)
+	return nil
+}
+
+// occurrenceWindow reads ?from= and ?to= (YYYY-MM-DD in the viewer's timezone), by default the next
+// 90 days, writing an error response on failure. The returned window ends before "to".
+func occurrenceWindow(c *gin.Context) (from, to time.Time, ok bool) {
+	loc := windowLocation(c)
+	now := time.Now().In(loc)
+	from = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	to = from.AddDate(0, 0, defaultOccurrenceDays)
+
+	var err error
+	if value := c.Query("from"); value != "" {
+		if from, err = parseLocalDate(value, loc); err != nil {
+			utils.ErrorResponse(c, 400, "Invalid from date. Use YYYY-MM-DD")
+			return from, to, false
+		}
+		to = from.AddDate(0, 0, defaultOccurrenceDays)
+	}
+	if value := c.Query("to"); value != "" {
+		if to, err = parseLocalDate(value, loc); err != nil {
+			utils.ErrorResponse(c, 400, "Invalid to date. Use YYYY-MM-DD")
+			return from, to, false
+		}
+		to = to.AddDate(0, 0, 1)
+	}
+
+	if !to.After(from) {
+		utils.ErrorResponse(c, 400, "The to date cannot be before the from date")
+		return from, to, false
+	}
+	if to.After(from.AddDate(0, 0, maxOccurrenceDays+1)) {
+		utils.ErrorResponse(c, 400, "The date window cannot be longer than 366 days")
+		return from, to, false
+	}
+	return from, to, true
+}
+
+// searchWindow turns the optional bounds of the search endpoints into an expansion window
+func searchWindow(from, to *time.Time) (time.Time, time.Time) {
+	switch {
+	case from == nil:
+		return to.AddDate(0, 0, -maxOccurrenceDays), *to
+	case to == nil:
+		return *from, from.AddDate(0, 0, maxOccurrenceDays)
+	case to.After(from.AddDate(0, 0, maxOccurrenceDays+1)):
+		return *from, from.AddDate(0, 0, maxOccurrenceDays+1)
+	}
+	return *from, *to
+}
+
+// expandEvents turns recurring events into one response per occurrence starting from "from"
+// and before "to"; other events are returned as they are
+func expandEvents(events []models.Event, from, to time.Time) []models.EventResponse {
+	responses := make([]models.EventResponse, 0, len(events))
+	for i := range events {
+		if events[i].Recurrence == nil {
+			responses = append(responses, events[i].ToResponse())
+			continue
+		}
+		responses = append(responses, expandEvent(&events[i], from, to)...)
+	}
+	return responses
+}
+
+// expandEvent returns the occurrences of a recurring event starting in the window, with
+// "this occurrence" changes applied. An occurrence moved by an override is placed at its new time.
+func expandEvent(event *models.Event, from, to time.Time) []models.EventResponse {
+	set, err := recurrenceSet(event)
+	if err != nil {
+		return nil
+	}
+
+	overrides := make(map[string]models.EventOccurrenceOverride, len(event.Overrides))
+	for _, override := range event.Overrides {
+		overrides[override.OccurrenceDate] = override
+	}
+
+	// Occurrences are local dates of the event; a day of margin covers the timezone difference
+	zone := event.Zone()
+	dates := map[string]bool{}
+	for _, date := range set.Between(dateOf(from.In(zone)).AddDate(0, 0, -1), dateOf(to.In(zone)).AddDate(0, 0, 1)) {
+		dates[recurrence.FormatDate(date)] = true
+	}
+	// Occurrences moved into the window from outside of it
+	for original, override := range overrides {
+		if override.StartAt == nil || dates[original] {
+			continue
+		}
+		if date, err := recurrence.ParseDate(original); err == nil && set.Includes(date) {
+			dates[original] = true
+		}
+	}
+
+	var responses []models.EventResponse
+	for original := range dates {
+		resp := occurrenceResponse(event, original, overrides[original])
+
+		// The window applies to the time the occurrence actually starts
+		if resp.StartAt.Before(from) || !resp.StartAt.Before(to) {
+			continue
+		}
+		responses = append(responses, resp)
+	}
+
+	sort.Slice(responses, func(i, j int) bool {
+		return responses[i].StartAt.Before(responses[j].StartAt)
+	})
+	return responses
+}
+
+// occurrenceResponse builds the response of one occurrence of a recurring event
+func occurrenceResponse(event *models.Event, occurrenceDate string, override models.EventOccurrenceOverride) models.EventResponse {
+	resp := event.ToResponse()
+	resp.Overrides = nil
+	resp.OccurrenceDate = occurrenceDate
+	resp.StartAt, resp.EndAt = occurrenceTimes(event, occurrenceDate)
+
+	if override.Title != "" {
+		resp.Title = override.Title
+	}
+	if override.Description != "" {
+		resp.Description = override.Description
+	}
+	if override.StartAt != nil {
+		resp.StartAt = *override.StartAt
+	}
+	if override.EndAt != nil {
+		resp.EndAt = *override.EndAt
+	}
+	if override.Location != "" {
+		resp.Location = override.Location
+	}
+	return resp
+}
+
+// checkOccurrence makes sure the date is an occurrence of the event, writing an error response on failure
+func checkOccurrence(c *gin.Context, event *models.Event, occurrenceDate string) bool {
+	if event.Recurrence == nil {
+		utils.ErrorResponse(c, 400, "occurrence_date can only be used with recurring events")
+		return false
+	}
+
+	date, err := recurrence.ParseDate(occurrenceDate)
+	if err != nil {
+		utils.ErrorResponse(c, 400, "Invalid occurrence_date. Use YYYY-MM-DD")
+		return false
+	}
+
+	set, err := recurrenceSet(event)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Invalid recurrence stored for this event")
+		return false
+	}
+	if !set.Includes(date) {
+		utils.ErrorResponse(c, 400, "The event has no occurrence on this date")
+		return false
+	}
+	return true
+}
+
+// statusKey identifies an answer for a whole event (empty OccurrenceDate) or for one occurrence
+type statusKey struct {
+	EventID        primitive.ObjectID
+	OccurrenceDate string
+}
+
+// effectiveStatus returns the answer for an occurrence, falling back to the answer for the whole event
+func effectiveStatus(statuses map[statusKey]models.EventStatus, eventID primitive.ObjectID, occurrenceDate string) (models.EventStatus, bool) {
+	if occurrenceDate != "" {
+		if status, ok := statuses[statusKey{eventID, occurrenceDate}]; ok {
+			return status, true
+		}
+	}
+	status, ok := statuses[statusKey{eventID, ""}]
+	return status, ok
+}
+
+// occurrenceFilter matches event statuses of one occurrence, or the whole-event answer for an empty date
+func occurrenceFilter(occurrenceDate string) interface{} {
+	if occurrenceDate == "" {
+		return nil
+	}
+	return occurrenceDate
+}
+
+func parseDates(values []string) ([]time.Time, error) {
+	dates := make([]time.Time, 0, len(values))
+	for _, value := range values {
+		date, err := recurrence.ParseDate(value)
+		if err != nil {
+			return nil, err
+		}
+		dates = append(dates, date)
+	}
+	return dates, nil
+}
+
+// formatDates formats dates as sorted, unique YYYY-MM-DD strings
+func formatDates(dates []time.Time) []string {
+	seen := map[string]bool{}
+	values := make([]string, 0, len(dates))
+	for _, date := range dates {
+		value := recurrence.FormatDate(date)
+		if !seen[value] {
+			seen[value] = true
+			values = append(values, value)
+		}
+	}
+	sort.Strings(values)
+	if len(values) == 0 {
+		return nil
+	}
+	return values
+}
+
+// splitRecurrence splits a series at an occurrence: "before" ends the original series the day
+// before, "after" continues from the split date with the remaining COUNT or the original UNTIL
+func splitRecurrence(event *models.Event, splitDate string) (before, after *models.EventRecurrence, err error) {
+	set, err := recurrenceSet(event)
+	if err != nil {
+		return nil, nil, err
+	}
+	split, err := recurrence.ParseDate(splitDate)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	beforeRule, afterRule := *set.Rule, *set.Rule
+	if set.Rule.Count > 0 {
+		beforeRule.Count = set.CountBefore(split)
+		afterRule.Count = set.Rule.Count - beforeRule.Count
+	} else {
+		beforeRule.Until = split.AddDate(0, 0, -1)
+	}
+
+	before = &models.EventRecurrence{RRule: beforeRule.String()}
+	after = &models.EventRecurrence{RRule: afterRule.String()}
+	before.ExDates, after.ExDates = splitDateList(event.Recurrence.ExDates, splitDate)
+	before.RDates, after.RDates = splitDateList(event.Recurrence.RDates, splitDate)
+	return before, after, nil
+}
+
+// splitDateList splits YYYY-MM-DD dates into those before and those from the split date on
+func splitDateList(dates []string, splitDate string) (before, after []string) {
+	for _, date := range dates {
+		if date < splitDate {
+			before = append(before, date)
+		} else {
+			after = append(after, date)
+		}
+	}
+	return before, after
+}
+
+// splitOverrides splits occurrence overrides into those before and those from the split date on
+func splitOverrides(overrides []models.EventOccurrenceOverride, splitDate string) (before, after []models.EventOccurrenceOverride) {
+	for _, override := range overrides {
+		if override.OccurrenceDate < splitDate {
+			before = append(before, override)
+		} else {
+			after = append(after, override)
+		}
+	}
+	return before, after
+}
+
+// updateOccurrence stores "this occurrence" changes as an override of the series
+func updateOccurrence(c *gin.Context, event *models.Event, occurrenceDate string, changes bson.M) {
+	if len(changes) == 0 {
+		utils.ErrorResponse(c, 400, "No changes given for the occurrence")
+		return
+	}
+
+	override := findOverride(event, occurrenceDate)
+	overrides := make([]models.EventOccurrenceOverride, 0, len(event.Overrides)+1)
+	for _, existing := range event.Overrides {
+		if existing.OccurrenceDate != occurrenceDate {
+			overrides = append(overrides, existing)
+		}
+	}
+
+	if value, ok := changes["title"].(string); ok {
+		override.Title = value
+	}
+	if value, ok := changes["description"].(string); ok {
+		override.Description = value
+	}
+	if value, ok := changes["start_at"].(time.Time); ok {
+		override.StartAt = &value
+	}
+	if value, ok := changes["end_at"].(time.Time); ok {
+		override.EndAt = &value
+	}
+	if value, ok := changes["location"].(string); ok {
+		override.Location = value
+	}
+	overrides = append(overrides, override)
+
+	result, err := database.GetCollection("events").UpdateOne(
+		context.TODO(),
+		pinVersion(bson.M{"_id": event.ID}, event.Version),
+		bumpVersion(bson.M{"$set": bson.M{"overrides": overrides, "updated_at": time.Now()}}),
+	)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to update occurrence")
+		return
+	}
+	if result.MatchedCount == 0 {
+		versionConflict(c)
+		return
+	}
+	recordEventChange(c, models.HistoryUpdated, event, event.ID, map[string]interface{}{"occurrence_date": occurrenceDate})
+
+	utils.SuccessResponse(c, 200, "Occurrence updated successfully", localizeEvent(c, occurrenceResponse(event, occurrenceDate, override)))
+}
+
+// findOverride returns the changes made to an occurrence (empty when there are none)
+func findOverride(event *models.Event, occurrenceDate string) models.EventOccurrenceOverride {
+	for _, override := range event.Overrides {
+		if override.OccurrenceDate == occurrenceDate {
+			return override
+		}
+	}
+	return models.EventOccurrenceOverride{OccurrenceDate: occurrenceDate}
+}
+
+// splitSeries applies a "this and following" edit: the series ends before the occurrence
+// and a new series, linked by series_id, takes over from it with the changes applied
+func splitSeries(c *gin.Context, event *models.Event, req models.UpdateEventRequest, changes bson.M) {
+	before, after, err := splitRecurrence(event, req.OccurrenceDate)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Invalid recurrence stored for this event")
+		return
+	}
+	beforeOverrides, afterOverrides := splitOverrides(event.Overrides, req.OccurrenceDate)
+	previous := *event
+
+	// The new series starts with the occurrence (or its changed times)
+	following := *event
+	following.ID = primitive.NilObjectID
+	following.StartAt, following.EndAt = occurrenceTimes(event, req.OccurrenceDate)
+	applyEventChanges(&following, changes)
+
+	// Exceptions and answers keyed by the old dates only carry over when the dates stay the same
+	datesMoved := following.LocalDate() != req.OccurrenceDate || req.Recurrence != nil
+	if req.Recurrence != nil {
+		after = req.Recurrence
+	}
+	if datesMoved {
+		after.ExDates, after.RDates, afterOverrides = nil, nil, nil
+	}
+	if err := normalizeRecurrence(after, following.LocalDate()); err != nil {
+		utils.ErrorResponse(c, 400, "Invalid recurrence: "+err.Error())
+		return
+	}
+	following.Recurrence = after
+	following.Overrides = afterOverrides
+
+	seriesID := event.ID
+	if event.SeriesID != nil {
+		seriesID = *event.SeriesID
+	}
+	following.SeriesID = &seriesID
+	following.Version = 1
+	following.CreatedAt = time.Now()
+	following.UpdatedAt = time.Now()
+
+	collection := database.GetCollection("events")
+	result, err := collection.InsertOne(context.TODO(), following)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to create the following series")
+		return
+	}
+	following.ID = result.InsertedID.(primitive.ObjectID)
+
+	updated, err := collection.UpdateOne(
+		context.TODO(),
+		pinVersion(bson.M{"_id": event.ID}, event.Version),
+		bumpVersion(bson.M{"$set": bson.M{"recurrence": before, "overrides": beforeOverrides, "updated_at": time.Now()}}),
+	)
+	if err != nil || updated.MatchedCount == 0 {
+		// The following series must not outlive a failed split
+		collection.DeleteOne(context.TODO(), bson.M{"_id": following.ID})
+		if err != nil {
+			utils.ErrorResponse(c, 500, "Failed to update event")
+		} else {
+			versionConflict(c)
+		}
+		return
+	}
+	event.Recurrence = before
+	event.Overrides = beforeOverrides
+	recordEventChange(c, models.HistoryUpdated, &previous, event.ID, map[string]interface{}{"split_at": req.OccurrenceDate, "following_id": following.ID})
+	recordEventChange(c, models.HistoryCreated, nil, following.ID, map[string]interface{}{"split_from": event.ID})
+
+	// Answers for later occurrences follow them to the new series, answers for the whole series are copied
+	statuses := database.GetCollection("event_statuses")
+	seats := database.GetCollection("event_seats")
+	// The split itself is saved, so failures here are logged rather than answered
+	laterFilter := bson.M{"event_id": event.ID, "occurrence_date": bson.M{"$gte": req.OccurrenceDate}}
+	if datesMoved {
+		if _, err := statuses.DeleteMany(context.TODO(), laterFilter); err != nil {
+			log.Printf("Failed to delete the answers after %s of event %s: %v", req.OccurrenceDate, event.ID.Hex(), err)
+		}
+		if _, err := seats.DeleteMany(context.TODO(), laterFilter); err != nil {
+			log.Printf("Failed to delete the seats after %s of event %s: %v", req.OccurrenceDate, event.ID.Hex(), err)
+		}
+	} else {
+		if _, err := statuses.UpdateMany(context.TODO(), laterFilter, bson.M{"$set": bson.M{"event_id": following.ID}}); err != nil {
+			log.Printf("Failed to move the answers after %s of event %s to %s: %v", req.OccurrenceDate, event.ID.Hex(), following.ID.Hex(), err)
+		}
+		if _, err := seats.UpdateMany(context.TODO(), laterFilter, bson.M{"$set": bson.M{"event_id": following.ID}}); err != nil {
+			log.Printf("Failed to move the seats after %s of event %s to %s: %v", req.OccurrenceDate, event.ID.Hex(), following.ID.Hex(), err)
+		}
+	}
+
+	var seriesStatuses []models.EventStatus
+	cursor, err := statuses.Find(context.TODO(), bson.M{"event_id": event.ID, "occurrence_date": nil})
+	if err == nil {
+		err = cursor.All(context.TODO(), &seriesStatuses)
+		cursor.Close(context.TODO())
+	}
+	if err != nil {
+		log.Printf("Failed to load the series answers of event %s: %v", event.ID.Hex(), err)
+	} else if len(seriesStatuses) > 0 {
+		copies := make([]interface{}, 0, len(seriesStatuses))
+		for _, status := range seriesStatuses {
+			status.ID = primitive.NilObjectID
+			status.EventID = following.ID
+			copies = append(copies, status)
+		}
+		if _, err := statuses.InsertMany(context.TODO(), copies); err != nil {
+			log.Printf("Failed to copy the series answers of event %s to %s: %v", event.ID.Hex(), following.ID.Hex(), err)
+		}
+	}
+
+	capacityChanged(event, &following)
+
+	utils.SuccessResponse(c, 200, "Event series split successfully", gin.H{
+		"series":    localizeEvent(c, event.ToResponse()),
+		"following": localizeEvent(c, following.ToResponse()),
+	})
+}
+
+// deleteOccurrence removes a single occurrence by adding it to the series' exdates
+func deleteOccurrence(c *gin.Context, event *models.Event, occurrenceDate string) {
+	result, err := database.GetCollection("events").UpdateOne(
+		context.TODO(),
+		pinVersion(bson.M{"_id": event.ID}, event.Version),
+		bumpVersion(bson.M{
+			"$addToSet": bson.M{"recurrence.exdates": occurrenceDate},
+			"$pull":     bson.M{"overrides": bson.M{"occurrence_date": occurrenceDate}},
+			"$set":      bson.M{"updated_at": time.Now()},
+		}),
+	)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to delete occurrence")
+		return
+	}
+	if result.MatchedCount == 0 {
+		versionConflict(c)
+		return
+	}
+	recordEventChange(c, models.HistoryDeleted, event, event.ID, map[string]interface{}{"occurrence_date": occurrenceDate})
+
+	filter := bson.M{"event_id": event.ID, "occurrence_date": occurrenceDate}
+	database.GetCollection("event_statuses").DeleteMany(context.TODO(), filter)
+	database.GetCollection("event_seats").DeleteMany(context.TODO(), filter)
+
+	utils.SuccessResponse(c, 200, "Occurrence deleted successfully", nil)
+}
+
+// endSeriesBefore deletes an occurrence and all that follow it by ending the series the day before
+func endSeriesBefore(c *gin.Context, event *models.Event, occurrenceDate string) {
+	before, _, err := splitRecurrence(event, occurrenceDate)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Invalid recurrence stored for this event")
+		return
+	}
+	overrides, _ := splitOverrides(event.Overrides, occurrenceDate)
+
+	result, err := database.GetCollection("events").UpdateOne(
+		context.TODO(),
+		pinVersion(bson.M{"_id": event.ID}, event.Version),
+		bumpVersion(bson.M{"$set": bson.M{"recurrence": before, "overrides": overrides, "updated_at": time.Now()}}),
+	)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to delete occurrences")
+		return
+	}
+	if result.MatchedCount == 0 {
+		versionConflict(c)
+		return
+	}
+	recordEventChange(c, models.HistoryDeleted, event, event.ID, map[string]interface{}{"from_occurrence_date": occurrenceDate})
+
+	laterFilter := bson.M{"event_id": event.ID, "occurrence_date": bson.M{"$gte": occurrenceDate}}
+	database.GetCollection("event_statuses").DeleteMany(context.TODO(), laterFilter)
+	database.GetCollection("event_seats").DeleteMany(context.TODO(), laterFilter)
+
+	utils.SuccessResponse(c, 200, "Occurrences deleted successfully", nil)
+}
+
+// dateRangeFilter matches events starting within the range, and recurring events that start
+// before its end (their occurrences are checked after expansion)
+func dateRangeFilter(from, to *time.Time) bson.M {
+	startFilter := bson.M{}
+	seriesFilter := bson.M{"recurrence": bson.M{"$ne": nil}}
+	if from != nil {
+		startFilter["$gte"] = *from
+	}
+	if to != nil {
+		startFilter["$lt"] = *to
+		seriesFilter["start_at"] = bson.M{"$lt": *to}
+	}
+
+	return bson.M{"$or": bson.A{
+		bson.M{"start_at": startFilter},
+		seriesFilter,
+	}}
+}
+
+// expandSearchResults builds the search responses, expanding recurring events to their
+// occurrences within the searched date range
+func expandSearchResults(events []models.Event, from, to *time.Time) []models.EventResponse {
+	if from != nil || to != nil {
+		windowFrom, windowTo := searchWindow(from, to)
+		return expandEvents(events, windowFrom, windowTo)
+	}
+
+	responses := make([]models.EventResponse, 0, len(events))
+	for _, event := range events {
+		responses = append(responses, event.ToResponse())
+	}
+	return responses
+}
+
+// parseDateRange reads the optional start and end dates of the search endpoints (YYYY-MM-DD in the
+// viewer's timezone, end date included), writing an error response on failure
+func parseDateRange(c *gin.Context, startDate, endDate string) (from, to *time.Time, ok bool) {
+	loc := windowLocation(c)
+	if startDate != "" {
+		date, err := parseLocalDate(startDate, loc)
+		if err != nil {
+			utils.ErrorResponse(c, 400, "Invalid start_date. Use YYYY-MM-DD")
+			return nil, nil, false
+		}
+		from = &date
+	}
+	if endDate != "" {
+		date, err := parseLocalDate(endDate, loc)
+		if err != nil {
+			utils.ErrorResponse(c, 400, "Invalid end_date. Use YYYY-MM-DD")
+			return nil, nil, false
+		}
+		date = date.AddDate(0, 0, 1)
+		to = &date
+	}
+	return from, to, true
+}
@@ -0,0 +1,133 @@
+package controllers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+	"tools-backend/models"
+)
+
+func TestOccurrenceTimesAcrossDaylightSaving(t *testing.T) {
+	paris := &models.Event{
+		StartAt:  time.Date(2026, time.March, 27, 8, 0, 0, 0, time.UTC), // 09:00 CET
+		EndAt:    time.Date(2026, time.March, 27, 9, 30, 0, 0, time.UTC),
+		Timezone: "Europe/Paris",
+	}
+	newYork := &models.Event{
+		StartAt:  time.Date(2026, time.March, 6, 23, 30, 0, 0, time.UTC), // 18:30 EST
+		EndAt:    time.Date(2026, time.March, 7, 1, 30, 0, 0, time.UTC),
+		Timezone: "America/New_York",
+	}
+	night := &models.Event{
+		StartAt:  time.Date(2026, time.March, 27, 1, 30, 0, 0, time.UTC), // 02:30 CET
+		EndAt:    time.Date(2026, time.March, 27, 2, 30, 0, 0, time.UTC),
+		Timezone: "Europe/Paris",
+	}
+
+	tests := []struct {
+		name      string
+		event     *models.Event
+		date      string
+		wantStart time.Time
+		wantEnd   time.Time
+	}{
+		{
+			name: "first occurrence", event: paris, date: "2026-03-27",
+			wantStart: time.Date(2026, time.March, 27, 8, 0, 0, 0, time.UTC),
+			wantEnd:   time.Date(2026, time.March, 27, 9, 30, 0, 0, time.UTC),
+		},
+		{
+			name: "after the spring change", event: paris, date: "2026-03-30",
+			wantStart: time.Date(2026, time.March, 30, 7, 0, 0, 0, time.UTC), // 09:00 CEST
+			wantEnd:   time.Date(2026, time.March, 30, 8, 30, 0, 0, time.UTC),
+		},
+		{
+			name: "after the autumn change", event: paris, date: "2026-10-26",
+			wantStart: time.Date(2026, time.October, 26, 8, 0, 0, 0, time.UTC), // 09:00 CET again
+			wantEnd:   time.Date(2026, time.October, 26, 9, 30, 0, 0, time.UTC),
+		},
+		{
+			name: "evening event ending the next UTC day", event: newYork, date: "2026-03-09",
+			wantStart: time.Date(2026, time.March, 9, 22, 30, 0, 0, time.UTC), // 18:30 EDT
+			wantEnd:   time.Date(2026, time.March, 10, 0, 30, 0, 0, time.UTC),
+		},
+		{
+			name: "local time skipped by the change", event: night, date: "2026-03-29",
+			wantStart: time.Date(2026, time.March, 29, 1, 30, 0, 0, time.UTC), // 02:30 does not exist, 03:30 CEST
+			wantEnd:   time.Date(2026, time.March, 29, 2, 30, 0, 0, time.UTC),
+		},
+		{
+			name: "invalid date keeps the event times", event: paris, date: "2026-02-30",
+			wantStart: paris.StartAt,
+			wantEnd:   paris.EndAt,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			startAt, endAt := occurrenceTimes(tt.event, tt.date)
+			if !startAt.Equal(tt.wantStart) || !endAt.Equal(tt.wantEnd) {
+				t.Errorf("occurrenceTimes = %s - %s, want %s - %s", startAt, endAt, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestSplitRecurrence(t *testing.T) {
+	tests := []struct {
+		name       string
+		start      time.Time
+		recurrence models.EventRecurrence
+		split      string
+		wantBefore models.EventRecurrence
+		wantAfter  models.EventRecurrence
+	}{
+		{
+			name:       "count is shared",
+			start:      time.Date(2026, time.January, 5, 9, 0, 0, 0, time.UTC),
+			recurrence: models.EventRecurrence{RRule: "FREQ=WEEKLY;BYDAY=MO;COUNT=6", ExDates: []string{"2026-01-12", "2026-02-02"}},
+			split:      "2026-01-26",
+			wantBefore: models.EventRecurrence{RRule: "FREQ=WEEKLY;COUNT=3;BYDAY=MO", ExDates: []string{"2026-01-12"}},
+			wantAfter:  models.EventRecurrence{RRule: "FREQ=WEEKLY;COUNT=3;BYDAY=MO", ExDates: []string{"2026-02-02"}},
+		},
+		{
+			name:       "until ends the first part the day before",
+			start:      time.Date(2026, time.January, 31, 9, 0, 0, 0, time.UTC),
+			recurrence: models.EventRecurrence{RRule: "FREQ=MONTHLY;BYMONTHDAY=-1;UNTIL=20261231", RDates: []string{"2026-02-15", "2026-06-15"}},
+			split:      "2026-04-30",
+			wantBefore: models.EventRecurrence{RRule: "FREQ=MONTHLY;UNTIL=20260429;BYMONTHDAY=-1", RDates: []string{"2026-02-15"}},
+			wantAfter:  models.EventRecurrence{RRule: "FREQ=MONTHLY;UNTIL=20261231;BYMONTHDAY=-1", RDates: []string{"2026-06-15"}},
+		},
+		{
+			name:       "endless series",
+			start:      time.Date(2026, time.January, 1, 9, 0, 0, 0, time.UTC),
+			recurrence: models.EventRecurrence{RRule: "FREQ=DAILY"},
+			split:      "2026-01-10",
+			wantBefore: models.EventRecurrence{RRule: "FREQ=DAILY;UNTIL=20260109"},
+			wantAfter:  models.EventRecurrence{RRule: "FREQ=DAILY"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recurrence := tt.recurrence
+			event := &models.Event{
+				StartAt:    tt.start,
+				EndAt:      tt.start.Add(time.Hour),
+				Timezone:   "UTC",
+				Recurrence: &recurrence,
+			}
+
+			before, after, err := splitRecurrence(event, tt.split)
+			if err != nil {
+				t.Fatalf("splitRecurrence: %v", err)
+			}
+			if !reflect.DeepEqual(*before, tt.wantBefore) {
+				t.Errorf("before = %+v, want %+v", *before, tt.wantBefore)
+			}
+			if !reflect.DeepEqual(*after, tt.wantAfter) {
+				t.Errorf("after = %+v, want %+v", *after, tt.wantAfter)
+			}
+		})
+	}
+}
@@ -0,0 +1,282 @@
+package controllers
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+	"tools-backend/config"
+	"tools-backend/database"
+	"tools-backend/models"
+	"tools-backend/oidc"
+	"tools-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// oidcStateTTL is how long the user has to complete the login at the provider
+const oidcStateTTL = 10 * time.Minute
+
+// oidcStateCookie holds the hash of the state in the browser that started the login
+const oidcStateCookie = "oidc_state"
+
+type OIDCController struct{}
+
+// ListProviders returns the configured identity providers (for the login page)
+func (oc *OIDCController) ListProviders(c *gin.Context) {
+	utils.SuccessResponse(c, 200, "Providers retrieved successfully", oidc.ProviderNames())
+}
+
+// Login starts the authorization code + PKCE flow and returns the provider URL to redirect to
+func (oc *OIDCController) Login(c *gin.Context) {
+	provider, ok := oidc.GetProvider(c.Param("provider"))
+	if !ok {
+		utils.ErrorResponse(c, 404, "Unknown identity provider")
+		return
+	}
+
+	state, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to start login")
+		return
+	}
+	nonce, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to start login")
+		return
+	}
+	verifier, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to start login")
+		return
+	}
+
+	authURL, err := provider.AuthCodeURL(state, nonce, oidc.CodeChallengeS256(verifier))
+	if err != nil {
+		log.Printf("OIDC discovery failed for %s: %v", provider.Name, err)
+		utils.ErrorResponse(c, 502, "Identity provider is unavailable")
+		return
+	}
+
+	_, err = database.GetCollection("oidc_states").InsertOne(context.TODO(), models.OIDCState{
+		State:        state,
+		Provider:     provider.Name,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    time.Now().Add(oidcStateTTL),
+		CreatedAt:    time.Now(),
+	})
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to start login")
+		return
+	}
+	setOIDCStateCookie(c, state)
+
+	// Browsers can follow the redirect directly; API clients get the URL as JSON
+	if c.Query("redirect") == "true" {
+		c.Redirect(302, authURL)
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Redirect the user to the authorization URL", gin.H{
+		"authorization_url": authURL,
+		"state":             state,
+	})
+}
+
+// Callback completes the flow: it validates the ID token and links or creates the user
+func (oc *OIDCController) Callback(c *gin.Context) {
+	provider, ok := oidc.GetProvider(c.Param("provider"))
+	if !ok {
+		utils.ErrorResponse(c, 404, "Unknown identity provider")
+		return
+	}
+
+	// The authorization response arrives as query parameters (GET) or is posted by the frontend
+	var req models.OIDCCallbackRequest
+	if err := c.ShouldBind(&req); err != nil {
+		utils.ErrorResponse(c, 400, "Invalid request data")
+		return
+	}
+
+	if req.Error != "" {
+		utils.ErrorResponse(c, 401, "Login was cancelled or denied by the identity provider")
+		return
+	}
+
+	if req.Code == "" || req.State == "" {
+		utils.ErrorResponse(c, 400, "code and state are required")
+		return
+	}
+
+	// The state must come back to the browser that started the login, otherwise an attacker could
+	// send a victim to the callback with the attacker's code and sign them into the attacker's account
+	if !oidcStateCookieMatches(c, req.State) {
+		utils.ErrorResponse(c, 400, "Invalid or expired login state")
+		return
+	}
+
+	// Each state can only be used once
+	var pending models.OIDCState
+	err := database.GetCollection("oidc_states").FindOneAndDelete(context.TODO(), bson.M{
+		"state":      req.State,
+		"provider":   provider.Name,
+		"expires_at": bson.M{"$gt": time.Now()},
+	}).Decode(&pending)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			utils.ErrorResponse(c, 400, "Invalid or expired login state")
+		} else {
+			utils.ErrorResponse(c, 500, "Database error")
+		}
+		return
+	}
+
+	clearOIDCStateCookie(c)
+
+	tokens, err := provider.Exchange(req.Code, pending.CodeVerifier)
+	if err != nil {
+		log.Printf("OIDC code exchange failed for %s: %v", provider.Name, err)
+		utils.ErrorResponse(c, 401, "Failed to exchange authorization code")
+		return
+	}
+
+	claims, err := provider.VerifyIDToken(tokens.IDToken, pending.Nonce)
+	if err != nil {
+		log.Printf("OIDC id token rejected for %s: %v", provider.Name, err)
+		utils.ErrorResponse(c, 401, "Invalid ID token")
+		return
+	}
+
+	user, err := findOrCreateOIDCUser(c, provider.Name, claims)
+	if err != nil {
+		if err == errUnverifiedOIDCEmail {
+			utils.ErrorResponse(c, 403, "The identity provider did not return a verified email address")
+		} else {
+			utils.ErrorResponse(c, 500, "Failed to sign in")
+		}
+		return
+	}
+
+	completeLogin(c, user)
+}
+
+// setOIDCStateCookie binds a login to the browser that started it with a short-lived HttpOnly cookie
+// (SameSite=Lax, so it is still sent when the provider redirects back)
+func setOIDCStateCookie(c *gin.Context, state string) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, utils.HashToken(state), int(oidcStateTTL.Seconds()), "/api/v1/oidc", "", secureCookies(), true)
+}
+
+// clearOIDCStateCookie removes the state cookie once the state has been used
+func clearOIDCStateCookie(c *gin.Context) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, "", -1, "/api/v1/oidc", "", secureCookies(), true)
+}
+
+// oidcStateCookieMatches reports whether the state returned by the provider is the one this browser started
+func oidcStateCookieMatches(c *gin.Context, state string) bool {
+	cookie, err := c.Cookie(oidcStateCookie)
+	if err != nil || cookie == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(cookie), []byte(utils.HashToken(state))) == 1
+}
+
+// secureCookies reports whether cookies should only be sent over HTTPS (when APP_URL uses it)
+func secureCookies() bool {
+	return strings.HasPrefix(config.GetAppURL(), "https://")
+}
+
+// errUnverifiedOIDCEmail is returned when an unknown identity has no verified email to link by
+var errUnverifiedOIDCEmail = errors.New("identity provider did not return a verified email")
+
+// findOrCreateOIDCUser returns the user linked to the identity, linking an existing account
+// by verified email or creating a new one when needed
+func findOrCreateOIDCUser(c *gin.Context, provider string, claims *oidc.IDTokenClaims) (*models.User, error) {
+	collection := database.GetCollection("users")
+	var user models.User
+
+	// Already linked
+	err := collection.FindOne(context.TODO(), bson.M{
+		"identities": bson.M{"$elemMatch": bson.M{"provider": provider, "subject": claims.Subject}},
+	}).Decode(&user)
+	if err == nil {
+		return &user, nil
+	}
+	if err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+
+	// Linking by email is only safe when the provider vouches for the address
+	if claims.Email == "" || !claims.EmailVerified {
+		return nil, errUnverifiedOIDCEmail
+	}
+
+	now := time.Now()
+	identity := models.UserIdentity{
+		Provider: provider,
+		Subject:  claims.Subject,
+		Email:    claims.Email,
+		LinkedAt: now,
+	}
+
+	err = collection.FindOne(context.TODO(), bson.M{"email": claims.Email}).Decode(&user)
+	if err == nil {
+		update := bson.M{
+			"$push": bson.M{"identities": identity},
+			"$set":  bson.M{"updated_at": now},
+		}
+		verifiedNow := user.VerifiedAt == nil
+		if verifiedNow {
+			update["$set"].(bson.M)["verified_at"] = now
+			user.VerifiedAt = &now
+		}
+		if _, err := collection.UpdateOne(context.TODO(), bson.M{"_id": user.ID}, update); err != nil {
+			return nil, err
+		}
+		user.Identities = append(user.Identities, identity)
+
+		// The provider confirmed the address the account never verified
+		if verifiedNow {
+			claimInvitations(c, &user)
+		}
+		return &user, nil
+	}
+	if err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+
+	name := claims.Name
+	if len(name) < 2 {
+		name = strings.Split(claims.Email, "@")[0]
+	}
+
+	// Accounts created through a provider have no password until the user sets one
+	user = models.User{
+		Name:       name,
+		Email:      claims.Email,
+		VerifiedAt: &now,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+		Identities: []models.UserIdentity{identity},
+	}
+
+	result, err := collection.InsertOne(context.TODO(), user)
+	if err != nil {
+		return nil, err
+	}
+	user.ID = result.InsertedID.(primitive.ObjectID)
+
+	// Guest invitations follow the address only when the provider vouches for it
+	if claims.EmailVerified {
+		claimInvitations(c, &user)
+	}
+	return &user, nil
+}
@@ -0,0 +1,56 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestOIDCStateCookie(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// Login sets the cookie in the browser that starts the flow
+	recorder := httptest.NewRecorder()
+	login, _ := gin.CreateTestContext(recorder)
+	login.Request = httptest.NewRequest(http.MethodGet, "/api/v1/oidc/corp/login", nil)
+	setOIDCStateCookie(login, "state-1")
+
+	cookies := recorder.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != oidcStateCookie {
+		t.Fatalf("expected the %s cookie, got %v", oidcStateCookie, cookies)
+	}
+	cookie := cookies[0]
+	if !cookie.HttpOnly || cookie.SameSite != http.SameSiteLaxMode || cookie.Path != "/api/v1/oidc" || cookie.MaxAge <= 0 {
+		t.Errorf("unexpected cookie attributes %+v", cookie)
+	}
+	if cookie.Value == "state-1" {
+		t.Error("the cookie should hold a hash of the state, not the state")
+	}
+
+	tests := []struct {
+		name   string
+		cookie *http.Cookie
+		state  string
+		want   bool
+	}{
+		{name: "same browser", cookie: cookie, state: "state-1", want: true},
+		{name: "state started by another browser", cookie: cookie, state: "state-2", want: false},
+		{name: "no cookie (login CSRF)", state: "state-1", want: false},
+		{name: "empty cookie", cookie: &http.Cookie{Name: oidcStateCookie, Value: ""}, state: "state-1", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			callback, _ := gin.CreateTestContext(httptest.NewRecorder())
+			callback.Request = httptest.NewRequest(http.MethodGet, "/api/v1/oidc/corp/callback?code=c&state="+tt.state, nil)
+			if tt.cookie != nil {
+				callback.Request.AddCookie(&http.Cookie{Name: tt.cookie.Name, Value: tt.cookie.Value})
+			}
+
+			if got := oidcStateCookieMatches(callback, tt.state); got != tt.want {
+				t.Errorf("oidcStateCookieMatches = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package controllers
+
+import (
+	"context"
+	"sort"
+	"time"
+	"tools-backend/database"
+	"tools-backend/models"
+	"tools-backend/policies"
+	"tools-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetPublicEvent returns a public or unlisted event without authentication (no participant list)
+func (ec *EventController) GetPublicEvent(c *gin.Context) {
+	eventObjectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, 400, "Invalid event ID")
+		return
+	}
+
+	var event models.Event
+	err = database.GetCollection("events").FindOne(context.TODO(), notDeleted(bson.M{"_id": eventObjectID})).Decode(&event)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			utils.ErrorResponse(c, 404, "Event not found")
+		} else {
+			utils.ErrorResponse(c, 500, "Failed to fetch event")
+		}
+		return
+	}
+
+	if !policies.CanViewAnonymously(&event) {
+		utils.ErrorResponse(c, 404, "Event not found")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Event retrieved successfully", localizeEvent(c, event.ToResponse()).Public())
+}
+
+// DiscoverEvents lists upcoming public events soonest first (window from ?from= to ?to=, paginated
+// with ?page= and ?limit=). Pages are taken in the query, one event each; a recurring event lists
+// its occurrences in the window on the page it falls on.
+func (ec *EventController) DiscoverEvents(c *gin.Context) {
+	from, to, ok := occurrenceWindow(c)
+	if !ok {
+		return
+	}
+	page, limit := parsePagination(c, 20, 100)
+
+	// Only what has not started yet
+	now := time.Now()
+	upcomingFrom := from
+	if now.After(upcomingFrom) {
+		upcomingFrom = now
+	}
+	filter := notDeleted(bson.M{
+		"visibility": models.VisibilityPublic,
+		"$and":       bson.A{dateRangeFilter(&upcomingFrom, &to)},
+	})
+
+	collection := database.GetCollection("events")
+	total, err := collection.CountDocuments(context.TODO(), filter)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to count events")
+		return
+	}
+
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "start_at", Value: 1}, {Key: "_id", Value: 1}}).
+		SetSkip((page - 1) * limit).
+		SetLimit(limit)
+	cursor, err := collection.Find(context.TODO(), filter, findOptions)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to fetch events")
+		return
+	}
+	defer cursor.Close(context.TODO())
+
+	var events []models.Event
+	if err = cursor.All(context.TODO(), &events); err != nil {
+		utils.ErrorResponse(c, 500, "Failed to decode events")
+		return
+	}
+
+	upcoming := []models.EventResponse{}
+	for _, occurrence := range expandEvents(events, from, to) {
+		if occurrence.StartAt.After(now) {
+			upcoming = append(upcoming, occurrence)
+		}
+	}
+	sort.SliceStable(upcoming, func(i, j int) bool { return upcoming[i].StartAt.Before(upcoming[j].StartAt) })
+
+	responses := make([]models.PublicEventResponse, 0, len(upcoming))
+	for _, occurrence := range localizeEvents(c, upcoming) {
+		responses = append(responses, occurrence.Public())
+	}
+
+	utils.SuccessResponse(c, 200, "Events retrieved successfully", gin.H{
+		"events":     responses,
+		"pagination": paginationMeta(page, limit, total),
+	})
+}
+
+// JoinEvent makes the current user an attendee of a public or internal event; they then answer
+// like any invited attendee
+func (ec *EventController) JoinEvent(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	event, ok := loadEvent(c)
+	if !ok {
+		return
+	}
+
+	if !policies.CanViewEvent(actor, event) {
+		utils.ErrorResponse(c, 404, "Event not found")
+		return
+	}
+	if findParticipant(event, actor.UserID) != nil {
+		utils.ErrorResponse(c, 400, "You are already a participant of this event")
+		return
+	}
+	if !policies.CanJoin(actor, event) {
+		utils.ErrorResponse(c, 403, "This event cannot be joined, please ask an organizer for an invitation")
+		return
+	}
+	if !checkNotCancelled(c, event) {
+		return
+	}
+
+	participant := models.EventParticipant{UserID: actor.UserID, Role: models.RoleAttendee}
+	result, err := database.GetCollection("events").UpdateOne(
+		context.TODO(),
+		bson.M{"_id": event.ID, "participants.user_id": bson.M{"$ne": actor.UserID}},
+		bumpVersion(bson.M{"$push": bson.M{"participants": participant}, "$set": bson.M{"updated_at": time.Now()}}),
+	)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to join event")
+		return
+	}
+	if result.ModifiedCount == 0 {
+		utils.ErrorResponse(c, 400, "You are already a participant of this event")
+		return
+	}
+
+	recordEventChange(c, models.HistoryJoined, event, event.ID, nil)
+
+	event.Participants = append(event.Participants, participant)
+	utils.SuccessResponse(c, 200, "You joined the event", localizeEvent(c, event.ToResponse()))
+}
+
+// visibleEventsFilter matches the events a user may find: their own, internal and public ones
+// (unlisted events are only reachable by ID)
+func visibleEventsFilter(userID primitive.ObjectID) bson.M {
+	return bson.M{"$or": bson.A{
+		bson.M{"participants.user_id": userID},
+		bson.M{"visibility": bson.M{"$in": bson.A{models.VisibilityInternal, models.VisibilityPublic}}},
+	}}
+}
+
+// hideParticipants empties the participant list of the events the current user does not take part in
+func hideParticipants(c *gin.Context, responses []models.EventResponse) []models.EventResponse {
+	userID, _ := primitive.ObjectIDFromHex(c.GetString("user_id"))
+	if models.PlatformRole(c.GetString("user_role")).Can(models.PermissionViewAnyEvent) {
+		return responses
+	}
+
+	for i := range responses {
+		participant := false
+		for _, p := range responses[i].Participants {
+			if p.UserID == userID {
+				participant = true
+				break
+			}
+		}
+		if !participant {
+			responses[i].Participants = []models.EventParticipant{}
+		}
+	}
+	return responses
+}
@@ -3,6 +3,7 @@ package controllers
 import (
 	"context"
 	"regexp"
+	"time"
 	"tools-backend/database"
 	"tools-backend/models"
 	"tools-backend/utils"
@@ -32,6 +33,11 @@ func (sc *SearchController) SearchEvents(c *gin.Context) {
 		return
 	}
 
+	from, to, ok := parseDateRange(c, req.StartDate, req.EndDate)
+	if !ok {
+		return
+	}
+
 	userIDInterface, exists := c.Get("user_id")
 	if !exists {
 		utils.ErrorResponse(c, 401, "User ID not found in token")
@@ -51,10 +57,10 @@ func (sc *SearchController) SearchEvents(c *gin.Context) {
 	}
 
 	// Build filter
-	filter := buildEventSearchFilter(userObjectID, req)
+	filter := buildEventSearchFilter(userObjectID, req, from, to)
 
 	collection := database.GetCollection("events")
-	cursor, err := collection.Find(context.TODO(), filter)
+	cursor, err := collection.Find(context.TODO(), notDeleted(filter))
 	if err != nil {
 		utils.ErrorResponse(c, 500, "Failed to search events")
 		return
@@ -67,10 +73,7 @@ func (sc *SearchController) SearchEvents(c *gin.Context) {
 		return
 	}
 
-	eventResponses := make([]models.EventResponse, 0, len(events))
-	for _, event := range events {
-		eventResponses = append(eventResponses, event.ToResponse())
-	}
+	eventResponses := localizeEvents(c, hideParticipants(c, expandSearchResults(events, from, to)))
 
 	utils.SuccessResponse(c, 200, "Search completed successfully", gin.H{
 		"total_results": len(eventResponses),
@@ -109,7 +112,7 @@ func (sc *SearchController) GetAllUserEvents(c *gin.Context) {
 		},
 	}
 
-	cursor, err := collection.Find(context.TODO(), filter)
+	cursor, err := collection.Find(context.TODO(), notDeleted(filter))
 	if err != nil {
 		utils.ErrorResponse(c, 500, "Failed to fetch events")
 		return
@@ -127,7 +130,7 @@ func (sc *SearchController) GetAllUserEvents(c *gin.Context) {
 		eventResponses = append(eventResponses, event.ToResponse())
 	}
 
-	utils.SuccessResponse(c, 200, "All user events retrieved successfully", eventResponses)
+	utils.SuccessResponse(c, 200, "All user events retrieved successfully", localizeEvents(c, eventResponses))
 }
 
 // FilterEventsByDate returns events within a date range
@@ -135,6 +138,12 @@ func (sc *SearchController) FilterEventsByDate(c *gin.Context) {
 	startDate := c.Query("start_date")
 	endDate := c.Query("end_date")
 
+	// Dates are days in the viewer's timezone
+	from, to, ok := parseDateRange(c, startDate, endDate)
+	if !ok {
+		return
+	}
+
 	userIDInterface, exists := c.Get("user_id")
 	if !exists {
 		utils.ErrorResponse(c, 401, "User ID not found in token")
@@ -153,31 +162,18 @@ func (sc *SearchController) FilterEventsByDate(c *gin.Context) {
 		return
 	}
 
-	// Build filter
+	// Build filter (every event the user may see)
 	filter := bson.M{
-		"participants": bson.M{
-			"$elemMatch": bson.M{
-				"user_id": userObjectID,
-			},
-		},
+		"$and": bson.A{visibleEventsFilter(userObjectID)},
 	}
 
-	// Add date range filter if provided
+	// Add date range filter if provided (recurring events are matched by their occurrences below)
 	if startDate != "" || endDate != "" {
-		dateFilter := bson.M{}
-		if startDate != "" {
-			dateFilter["$gte"] = startDate
-		}
-		if endDate != "" {
-			dateFilter["$lte"] = endDate
-		}
-		if len(dateFilter) > 0 {
-			filter["date"] = dateFilter
-		}
+		filter["$and"] = append(filter["$and"].(bson.A), dateRangeFilter(from, to))
 	}
 
 	collection := database.GetCollection("events")
-	cursor, err := collection.Find(context.TODO(), filter)
+	cursor, err := collection.Find(context.TODO(), notDeleted(filter))
 	if err != nil {
 		utils.ErrorResponse(c, 500, "Failed to filter events")
 		return
@@ -190,10 +186,7 @@ func (sc *SearchController) FilterEventsByDate(c *gin.Context) {
 		return
 	}
 
-	eventResponses := make([]models.EventResponse, 0, len(events))
-	for _, event := range events {
-		eventResponses = append(eventResponses, event.ToResponse())
-	}
+	eventResponses := localizeEvents(c, hideParticipants(c, expandSearchResults(events, from, to)))
 
 	utils.SuccessResponse(c, 200, "Events filtered by date successfully", eventResponses)
 }
@@ -229,11 +222,7 @@ func (sc *SearchController) FilterEventsByKeyword(c *gin.Context) {
 	regexPattern := bson.M{"$regex": keyword, "$options": "i"}
 
 	filter := bson.M{
-		"participants": bson.M{
-			"$elemMatch": bson.M{
-				"user_id": userObjectID,
-			},
-		},
+		"$and": bson.A{visibleEventsFilter(userObjectID)},
 		"$or": bson.A{
 			bson.M{"title": regexPattern},
 			bson.M{"description": regexPattern},
@@ -241,7 +230,7 @@ func (sc *SearchController) FilterEventsByKeyword(c *gin.Context) {
 	}
 
 	collection := database.GetCollection("events")
-	cursor, err := collection.Find(context.TODO(), filter)
+	cursor, err := collection.Find(context.TODO(), notDeleted(filter))
 	if err != nil {
 		utils.ErrorResponse(c, 500, "Failed to search events")
 		return
@@ -261,7 +250,7 @@ func (sc *SearchController) FilterEventsByKeyword(c *gin.Context) {
 
 	utils.SuccessResponse(c, 200, "Events filtered by keyword successfully", gin.H{
 		"keyword": keyword,
-		"results": eventResponses,
+		"results": localizeEvents(c, hideParticipants(c, eventResponses)),
 	})
 }
 
@@ -313,7 +302,7 @@ func (sc *SearchController) FilterEventsByRole(c *gin.Context) {
 	}
 
 	collection := database.GetCollection("events")
-	cursor, err := collection.Find(context.TODO(), filter)
+	cursor, err := collection.Find(context.TODO(), notDeleted(filter))
 	if err != nil {
 		utils.ErrorResponse(c, 500, "Failed to filter events")
 		return
@@ -331,17 +320,22 @@ func (sc *SearchController) FilterEventsByRole(c *gin.Context) {
 		eventResponses = append(eventResponses, event.ToResponse())
 	}
 
-	utils.SuccessResponse(c, 200, "Events filtered by role successfully", eventResponses)
+	utils.SuccessResponse(c, 200, "Events filtered by role successfully", localizeEvents(c, eventResponses))
 }
 
 // Helper function to build search filter
-func buildEventSearchFilter(userObjectID primitive.ObjectID, req SearchRequest) bson.M {
-	filter := bson.M{
-		"participants": bson.M{
+func buildEventSearchFilter(userObjectID primitive.ObjectID, req SearchRequest, from, to *time.Time) bson.M {
+	// Without a role, search every event the user may see (their own, internal and public ones)
+	filter := bson.M{}
+	if req.UserRole == "" {
+		filter["$and"] = bson.A{visibleEventsFilter(userObjectID)}
+	} else {
+		filter["participants"] = bson.M{
 			"$elemMatch": bson.M{
 				"user_id": userObjectID,
+				"role":    req.UserRole,
 			},
-		},
+		}
 	}
 
 	// Apply keyword filter
@@ -353,18 +347,10 @@ func buildEventSearchFilter(userObjectID primitive.ObjectID, req SearchRequest)
 		}
 	}
 
-	// Apply date range filter
-	if req.StartDate != "" || req.EndDate != "" {
-		dateFilter := bson.M{}
-		if req.StartDate != "" {
-			dateFilter["$gte"] = req.StartDate
-		}
-		if req.EndDate != "" {
-			dateFilter["$lte"] = req.EndDate
-		}
-		if len(dateFilter) > 0 {
-			filter["date"] = dateFilter
-		}
+	// Apply date range filter (recurring events are expanded to their occurrences afterwards)
+	if from != nil || to != nil {
+		conditions, _ := filter["$and"].(bson.A)
+		filter["$and"] = append(conditions, dateRangeFilter(from, to))
 	}
 
 	// Apply location filter
@@ -373,16 +359,6 @@ func buildEventSearchFilter(userObjectID primitive.ObjectID, req SearchRequest)
 		filter["location"] = regexPattern
 	}
 
-	// Apply user role filter
-	if req.UserRole != "" {
-		// Update the $elemMatch to include role filter if specified
-		if elemMatch, exists := filter["participants"].(bson.M)["$elemMatch"]; exists {
-			if elemMatchObj, ok := elemMatch.(bson.M); ok {
-				elemMatchObj["role"] = req.UserRole
-			}
-		}
-	}
-
 	return filter
 }
 
@@ -400,6 +376,11 @@ func (sc *SearchController) AdvancedSearch(c *gin.Context) {
 		req.Location = c.Query("location")
 	}
 
+	from, to, ok := parseDateRange(c, req.StartDate, req.EndDate)
+	if !ok {
+		return
+	}
+
 	userIDInterface, exists := c.Get("user_id")
 	if !exists {
 		utils.ErrorResponse(c, 401, "User ID not found in token")
@@ -431,10 +412,10 @@ func (sc *SearchController) AdvancedSearch(c *gin.Context) {
 	}
 
 	// Build filter using helper function
-	filter := buildComplexSearchFilter(userObjectID, req)
+	filter := buildComplexSearchFilter(userObjectID, req, from, to)
 
 	collection := database.GetCollection("events")
-	cursor, err := collection.Find(context.TODO(), filter)
+	cursor, err := collection.Find(context.TODO(), notDeleted(filter))
 	if err != nil {
 		utils.ErrorResponse(c, 500, "Failed to search events")
 		return
@@ -447,10 +428,7 @@ func (sc *SearchController) AdvancedSearch(c *gin.Context) {
 		return
 	}
 
-	eventResponses := make([]models.EventResponse, 0, len(events))
-	for _, event := range events {
-		eventResponses = append(eventResponses, event.ToResponse())
-	}
+	eventResponses := localizeEvents(c, hideParticipants(c, expandSearchResults(events, from, to)))
 
 	utils.SuccessResponse(c, 200, "Advanced search completed successfully", gin.H{
 		"filters":       req,
@@ -460,21 +438,18 @@ func (sc *SearchController) AdvancedSearch(c *gin.Context) {
 }
 
 // Helper function for complex search filter
-func buildComplexSearchFilter(userObjectID primitive.ObjectID, req SearchRequest) bson.M {
-	// Base filter - user must be participant
-	elemMatch := bson.M{
-		"user_id": userObjectID,
-	}
-
-	// Add role to elemMatch if specified
-	if req.UserRole != "" {
-		elemMatch["role"] = req.UserRole
-	}
-
-	filter := bson.M{
-		"participants": bson.M{
-			"$elemMatch": elemMatch,
-		},
+func buildComplexSearchFilter(userObjectID primitive.ObjectID, req SearchRequest, from, to *time.Time) bson.M {
+	// Base filter - every event the user may see, or only theirs with a given role
+	filter := bson.M{}
+	if req.UserRole == "" {
+		filter["$and"] = bson.A{visibleEventsFilter(userObjectID)}
+	} else {
+		filter["participants"] = bson.M{
+			"$elemMatch": bson.M{
+				"user_id": userObjectID,
+				"role":    req.UserRole,
+			},
+		}
 	}
 
 	// Apply keyword filter on title and description
@@ -486,18 +461,10 @@ func buildComplexSearchFilter(userObjectID primitive.ObjectID, req SearchRequest
 		}
 	}
 
-	// Apply date range filter
-	if req.StartDate != "" || req.EndDate != "" {
-		dateFilter := bson.M{}
-		if req.StartDate != "" {
-			dateFilter["$gte"] = req.StartDate
-		}
-		if req.EndDate != "" {
-			dateFilter["$lte"] = req.EndDate
-		}
-		if len(dateFilter) > 0 {
-			filter["date"] = dateFilter
-		}
+	// Apply date range filter (recurring events are expanded to their occurrences afterwards)
+	if from != nil || to != nil {
+		conditions, _ := filter["$and"].(bson.A)
+		filter["$and"] = append(conditions, dateRangeFilter(from, to))
 	}
 
 	// Apply location filter
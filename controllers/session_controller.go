@@ -0,0 +1,116 @@
+package controllers
+
+import (
+	"context"
+	"time"
+	"tools-backend/database"
+	"tools-backend/models"
+	"tools-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type SessionController struct{}
+
+// GetSessions returns the active sessions (logins) of the current user
+func (sc *SessionController) GetSessions(c *gin.Context) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		utils.ErrorResponse(c, 401, "User ID not found in token")
+		return
+	}
+
+	userID, ok := userIDInterface.(string)
+	if !ok {
+		utils.ErrorResponse(c, 401, "Invalid user ID format")
+		return
+	}
+
+	userObjectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		utils.ErrorResponse(c, 400, "Invalid user ID")
+		return
+	}
+
+	filter := bson.M{
+		"user_id":    userObjectID,
+		"revoked_at": nil,
+		"expires_at": bson.M{"$gt": time.Now()},
+	}
+	findOptions := options.Find().SetSort(bson.D{{Key: "last_seen_at", Value: -1}})
+
+	cursor, err := database.GetCollection("sessions").Find(context.TODO(), filter, findOptions)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to fetch sessions")
+		return
+	}
+	defer cursor.Close(context.TODO())
+
+	var sessions []models.Session
+	if err = cursor.All(context.TODO(), &sessions); err != nil {
+		utils.ErrorResponse(c, 500, "Failed to process sessions")
+		return
+	}
+
+	currentSessionID := c.GetString("session_id")
+	sessionResponses := make([]models.SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		resp := session.ToResponse()
+		resp.Current = session.ID.Hex() == currentSessionID
+		sessionResponses = append(sessionResponses, resp)
+	}
+
+	utils.SuccessResponse(c, 200, "Sessions retrieved successfully", sessionResponses)
+}
+
+// RevokeSession logs out one of the current user's sessions (e.g. a lost laptop)
+func (sc *SessionController) RevokeSession(c *gin.Context) {
+	sessionObjectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, 400, "Invalid session ID")
+		return
+	}
+
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		utils.ErrorResponse(c, 401, "User ID not found in token")
+		return
+	}
+
+	userID, ok := userIDInterface.(string)
+	if !ok {
+		utils.ErrorResponse(c, 401, "Invalid user ID format")
+		return
+	}
+
+	userObjectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		utils.ErrorResponse(c, 400, "Invalid user ID")
+		return
+	}
+
+	count, err := database.GetCollection("sessions").CountDocuments(context.TODO(), bson.M{
+		"_id":        sessionObjectID,
+		"user_id":    userObjectID,
+		"revoked_at": nil,
+	})
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to fetch session")
+		return
+	}
+
+	if count == 0 {
+		utils.ErrorResponse(c, 404, "Session not found")
+		return
+	}
+
+	if err := revokeTokenFamily(sessionObjectID); err != nil {
+		utils.ErrorResponse(c, 500, "Failed to revoke session")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Session revoked successfully", nil)
+}
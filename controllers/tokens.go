@@ -0,0 +1,190 @@
+package controllers
+
+import (
+	"context"
+	"time"
+	"tools-backend/config"
+	"tools-backend/database"
+	"tools-backend/models"
+	"tools-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// startSession records a new login (device, IP) and returns its ID
+func startSession(c *gin.Context, user *models.User) (primitive.ObjectID, error) {
+	now := time.Now()
+	session := models.Session{
+		UserID:     user.ID,
+		UserAgent:  c.Request.UserAgent(),
+		IP:         c.ClientIP(),
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(config.GetRefreshTokenTTL()),
+	}
+
+	result, err := database.GetCollection("sessions").InsertOne(context.TODO(), session)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	return result.InsertedID.(primitive.ObjectID), nil
+}
+
+// issueTokenPair creates an access token and a refresh token for a session (the refresh token family)
+func issueTokenPair(user *models.User, familyID primitive.ObjectID) (gin.H, *models.RefreshToken, error) {
+	accessToken, err := utils.GenerateAccessToken(user.ID.Hex(), user.Email, jwt.MapClaims{
+		"email_verified": user.VerifiedAt != nil,
+		"sid":            familyID.Hex(),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rawRefreshToken, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	refreshToken := models.RefreshToken{
+		UserID:          user.ID,
+		TokenHash:       utils.HashToken(rawRefreshToken),
+		FamilyID:        familyID,
+		AccessJTI:       accessToken.JTI,
+		AccessExpiresAt: accessToken.ExpiresAt,
+		ExpiresAt:       now.Add(config.GetRefreshTokenTTL()),
+		CreatedAt:       now,
+	}
+
+	result, err := database.GetCollection("refresh_tokens").InsertOne(context.TODO(), refreshToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	refreshToken.ID = result.InsertedID.(primitive.ObjectID)
+
+	// Keep the session alive as long as its newest refresh token
+	database.GetCollection("sessions").UpdateOne(
+		context.TODO(),
+		bson.M{"_id": familyID},
+		bson.M{"$set": bson.M{"expires_at": refreshToken.ExpiresAt, "last_seen_at": now}},
+	)
+
+	return gin.H{
+		"token":              accessToken.Token,
+		"token_type":         "Bearer",
+		"expires_in":         int(config.GetAccessTokenTTL().Seconds()),
+		"refresh_token":      rawRefreshToken,
+		"refresh_expires_at": refreshToken.ExpiresAt,
+	}, &refreshToken, nil
+}
+
+// denyAccessToken puts an access token jti on the deny-list until it expires
+func denyAccessToken(jti string, userID primitive.ObjectID, expiresAt time.Time) error {
+	if jti == "" || expiresAt.Before(time.Now()) {
+		return nil
+	}
+
+	_, err := database.GetCollection("revoked_tokens").UpdateOne(
+		context.TODO(),
+		bson.M{"jti": jti},
+		bson.M{"$setOnInsert": models.RevokedToken{
+			JTI:       jti,
+			UserID:    userID,
+			ExpiresAt: expiresAt,
+			CreatedAt: time.Now(),
+		}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+// revokeRefreshTokens revokes every refresh token matching the filter and denies their live access tokens
+func revokeRefreshTokens(filter bson.M) error {
+	collection := database.GetCollection("refresh_tokens")
+	now := time.Now()
+
+	// Deny access tokens that were issued alongside these refresh tokens and are still alive
+	liveFilter := bson.M{"access_expires_at": bson.M{"$gt": now}}
+	for key, value := range filter {
+		liveFilter[key] = value
+	}
+
+	cursor, err := collection.Find(context.TODO(), liveFilter)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(context.TODO())
+
+	var tokens []models.RefreshToken
+	if err = cursor.All(context.TODO(), &tokens); err != nil {
+		return err
+	}
+
+	for _, t := range tokens {
+		if err := denyAccessToken(t.AccessJTI, t.UserID, t.AccessExpiresAt); err != nil {
+			return err
+		}
+	}
+
+	revokeFilter := bson.M{"revoked_at": nil}
+	for key, value := range filter {
+		revokeFilter[key] = value
+	}
+
+	_, err = collection.UpdateMany(context.TODO(), revokeFilter, bson.M{"$set": bson.M{"revoked_at": now}})
+	return err
+}
+
+// revokeTokenFamily revokes a session and all tokens rotated from it
+func revokeTokenFamily(familyID primitive.ObjectID) error {
+	_, err := database.GetCollection("sessions").UpdateOne(
+		context.TODO(),
+		bson.M{"_id": familyID, "revoked_at": nil},
+		bson.M{"$set": bson.M{"revoked_at": time.Now()}},
+	)
+	if err != nil {
+		return err
+	}
+	return revokeRefreshTokens(bson.M{"family_id": familyID})
+}
+
+// revokeAllUserTokens revokes every session of a user with their refresh and live access tokens
+func revokeAllUserTokens(userID primitive.ObjectID) error {
+	_, err := database.GetCollection("sessions").UpdateMany(
+		context.TODO(),
+		bson.M{"user_id": userID, "revoked_at": nil},
+		bson.M{"$set": bson.M{"revoked_at": time.Now()}},
+	)
+	if err != nil {
+		return err
+	}
+	return revokeRefreshTokens(bson.M{"user_id": userID})
+}
+
+// revokeOtherSessions revokes every session of a user except the one making the request
+func revokeOtherSessions(userID primitive.ObjectID, currentSessionID string) error {
+	cursor, err := database.GetCollection("sessions").Find(context.TODO(), bson.M{"user_id": userID, "revoked_at": nil})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(context.TODO())
+
+	var sessions []models.Session
+	if err = cursor.All(context.TODO(), &sessions); err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		if session.ID.Hex() == currentSessionID {
+			continue
+		}
+		if err := revokeTokenFamily(session.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,297 @@
+package controllers
+
+import (
+	"context"
+	"encoding/base64"
+	"time"
+	"tools-backend/config"
+	"tools-backend/database"
+	"tools-backend/models"
+	"tools-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/skip2/go-qrcode"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const recoveryCodeCount = 10
+
+type TwoFactorController struct{}
+
+// Setup starts 2FA enrollment and returns the otpauth URI and a QR code to scan
+func (tfc *TwoFactorController) Setup(c *gin.Context) {
+	user, ok := loadCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	if user.TwoFactorEnabled {
+		utils.ErrorResponse(c, 400, "Two-factor authentication is already enabled")
+		return
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to generate secret")
+		return
+	}
+
+	uri := utils.TOTPURI(config.GetAppName(), user.Email, secret)
+	png, err := qrcode.Encode(uri, qrcode.Medium, 256)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to generate QR code")
+		return
+	}
+
+	// The secret only becomes active once a code generated from it is confirmed
+	_, err = database.GetCollection("users").UpdateOne(
+		context.TODO(),
+		bson.M{"_id": user.ID},
+		bson.M{"$set": bson.M{"two_factor_pending_secret": secret, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to start two-factor setup")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Scan the QR code and confirm with a code from your authenticator app", gin.H{
+		"secret":      secret,
+		"otpauth_uri": uri,
+		"qr_png":      "data:image/png;base64," + base64.StdEncoding.EncodeToString(png),
+	})
+}
+
+// Confirm enables 2FA once the user proves the authenticator works, returning recovery codes
+func (tfc *TwoFactorController) Confirm(c *gin.Context) {
+	var req models.TwoFactorCodeRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, "Invalid request data")
+		return
+	}
+
+	// Validate request
+	if errors := utils.ValidateStruct(req); len(errors) > 0 {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	user, ok := loadCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	if user.TwoFactorEnabled {
+		utils.ErrorResponse(c, 400, "Two-factor authentication is already enabled")
+		return
+	}
+
+	if user.TwoFactorPendingSecret == "" {
+		utils.ErrorResponse(c, 400, "Start two-factor setup first")
+		return
+	}
+
+	step, valid := utils.ValidateTOTP(user.TwoFactorPendingSecret, req.Code, time.Now())
+	if !valid {
+		utils.ErrorResponse(c, 400, "Invalid two-factor code")
+		return
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to generate recovery codes")
+		return
+	}
+
+	_, err = database.GetCollection("users").UpdateOne(
+		context.TODO(),
+		bson.M{"_id": user.ID},
+		bson.M{
+			"$set": bson.M{
+				"two_factor_enabled":   true,
+				"two_factor_secret":    user.TwoFactorPendingSecret,
+				"two_factor_last_step": step,
+				"recovery_codes":       hashes,
+				"updated_at":           time.Now(),
+			},
+			"$unset": bson.M{"two_factor_pending_secret": ""},
+		},
+	)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to enable two-factor authentication")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Two-factor authentication enabled. Store these recovery codes somewhere safe", gin.H{
+		"recovery_codes": codes,
+	})
+}
+
+// Disable turns off 2FA after checking the password (or a recent sign-in) and a code
+func (tfc *TwoFactorController) Disable(c *gin.Context) {
+	var req models.TwoFactorDisableRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, "Invalid request data")
+		return
+	}
+
+	// Validate request
+	if errors := utils.ValidateStruct(req); len(errors) > 0 {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	user, ok := loadCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	if !user.TwoFactorEnabled {
+		utils.ErrorResponse(c, 400, "Two-factor authentication is not enabled")
+		return
+	}
+
+	// Accounts without a password (single sign-on) must have signed in recently instead
+	if !checkCurrentPassword(c, user, req.Password, "Invalid password") {
+		return
+	}
+
+	valid, err := verifyTwoFactor(user, req.Code, req.RecoveryCode)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to verify two-factor code")
+		return
+	}
+	if !valid {
+		utils.ErrorResponse(c, 401, "Invalid two-factor code")
+		return
+	}
+
+	_, err = database.GetCollection("users").UpdateOne(
+		context.TODO(),
+		bson.M{"_id": user.ID},
+		bson.M{
+			"$set": bson.M{"two_factor_enabled": false, "updated_at": time.Now()},
+			"$unset": bson.M{
+				"two_factor_secret":         "",
+				"two_factor_pending_secret": "",
+				"two_factor_last_step":      "",
+				"recovery_codes":            "",
+			},
+		},
+	)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to disable two-factor authentication")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Two-factor authentication disabled", nil)
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes (the old ones stop working)
+func (tfc *TwoFactorController) RegenerateRecoveryCodes(c *gin.Context) {
+	var req models.TwoFactorCodeRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, "Invalid request data")
+		return
+	}
+
+	// Validate request
+	if errors := utils.ValidateStruct(req); len(errors) > 0 {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	user, ok := loadCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	if !user.TwoFactorEnabled {
+		utils.ErrorResponse(c, 400, "Two-factor authentication is not enabled")
+		return
+	}
+
+	valid, err := verifyTwoFactor(user, req.Code, "")
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to verify two-factor code")
+		return
+	}
+	if !valid {
+		utils.ErrorResponse(c, 401, "Invalid two-factor code")
+		return
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to generate recovery codes")
+		return
+	}
+
+	_, err = database.GetCollection("users").UpdateOne(
+		context.TODO(),
+		bson.M{"_id": user.ID},
+		bson.M{"$set": bson.M{"recovery_codes": hashes, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to save recovery codes")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Recovery codes regenerated", gin.H{
+		"recovery_codes": codes,
+	})
+}
+
+// verifyTwoFactor checks a TOTP code (each time step is accepted once) or consumes a recovery code
+func verifyTwoFactor(user *models.User, code, recoveryCode string) (bool, error) {
+	collection := database.GetCollection("users")
+
+	if code != "" {
+		step, valid := utils.ValidateTOTP(user.TwoFactorSecret, code, time.Now())
+		if !valid {
+			return false, nil
+		}
+
+		// Only move forward in time, so a code cannot be replayed
+		result, err := collection.UpdateOne(
+			context.TODO(),
+			bson.M{"_id": user.ID, "two_factor_last_step": bson.M{"$not": bson.M{"$gte": step}}},
+			bson.M{"$set": bson.M{"two_factor_last_step": step}},
+		)
+		if err != nil {
+			return false, err
+		}
+		return result.ModifiedCount == 1, nil
+	}
+
+	if recoveryCode != "" {
+		hash := utils.HashRecoveryCode(recoveryCode)
+		result, err := collection.UpdateOne(
+			context.TODO(),
+			bson.M{"_id": user.ID, "recovery_codes": hash},
+			bson.M{"$pull": bson.M{"recovery_codes": hash}},
+		)
+		if err != nil {
+			return false, err
+		}
+		return result.ModifiedCount == 1, nil
+	}
+
+	return false, nil
+}
+
+// newRecoveryCodes returns fresh recovery codes and their hashes
+func newRecoveryCodes() ([]string, []string, error) {
+	codes, err := utils.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = utils.HashRecoveryCode(code)
+	}
+	return codes, hashes, nil
+}
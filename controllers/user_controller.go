@@ -2,14 +2,22 @@ package controllers
 
 import (
 	"context"
+	"fmt"
+	"net/url"
+	"time"
+	"tools-backend/config"
 	"tools-backend/database"
+	"tools-backend/mailer"
 	"tools-backend/models"
 	"tools-backend/utils"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type UserController struct{}
@@ -50,7 +58,8 @@ func (uc *UserController) SearchUsers(c *gin.Context) {
 	regexPattern := bson.M{"$regex": query, "$options": "i"}
 
 	filter := bson.M{
-		"_id": bson.M{"$ne": currentUserID}, // Exclude current user
+		"_id":          bson.M{"$ne": currentUserID}, // Exclude current user
+		"suspended_at": nil,                          // Suspended accounts cannot be invited
 		"$or": bson.A{
 			bson.M{"name": regexPattern},
 			bson.M{"email": regexPattern},
@@ -81,3 +90,445 @@ func (uc *UserController) SearchUsers(c *gin.Context) {
 
 	utils.SuccessResponse(c, 200, "Users found", userResponses)
 }
+
+// GetProfile returns the current user's profile
+func (uc *UserController) GetProfile(c *gin.Context) {
+	user, ok := loadCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Profile retrieved successfully", user.ToResponse())
+}
+
+// UpdateProfile updates the current user's profile
+func (uc *UserController) UpdateProfile(c *gin.Context) {
+	var req models.UpdateProfileRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, "Invalid request data")
+		return
+	}
+
+	// Validate request
+	if errors := utils.ValidateStruct(req); len(errors) > 0 {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	user, ok := loadCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	updateDoc := bson.M{"name": req.Name, "updated_at": now}
+
+	// Event times are shown in this timezone unless a request asks for another one
+	if req.Timezone != "" {
+		if _, err := utils.LoadTimezone(req.Timezone); err != nil {
+			utils.ErrorResponse(c, 400, "Invalid timezone. Use an IANA name such as Europe/Paris")
+			return
+		}
+		updateDoc["timezone"] = req.Timezone
+		user.Timezone = req.Timezone
+	}
+
+	_, err := database.GetCollection("users").UpdateOne(
+		context.TODO(),
+		bson.M{"_id": user.ID},
+		bson.M{"$set": updateDoc},
+	)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to update profile")
+		return
+	}
+
+	user.Name = req.Name
+	user.UpdatedAt = now
+	utils.SuccessResponse(c, 200, "Profile updated successfully", user.ToResponse())
+}
+
+// ChangePassword changes the password after checking the current one and logs out other sessions
+func (uc *UserController) ChangePassword(c *gin.Context) {
+	var req models.ChangePasswordRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, "Invalid request data")
+		return
+	}
+
+	// Validate request
+	if errors := utils.ValidateStruct(req); len(errors) > 0 {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	user, ok := loadCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	if !checkCurrentPassword(c, user, req.CurrentPassword, "Current password is incorrect") {
+		return
+	}
+
+	// Hash password
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to hash password")
+		return
+	}
+
+	_, err = database.GetCollection("users").UpdateOne(
+		context.TODO(),
+		bson.M{"_id": user.ID},
+		bson.M{
+			"$set":   bson.M{"password": string(hashedPassword), "updated_at": time.Now()},
+			"$unset": bson.M{"password_reset_required": ""},
+		},
+	)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to update password")
+		return
+	}
+
+	if err := revokeOtherSessions(user.ID, c.GetString("session_id")); err != nil {
+		utils.ErrorResponse(c, 500, "Failed to revoke other sessions")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Password changed successfully", nil)
+}
+
+// ChangeEmail starts an email change; the new address is used once it is confirmed
+func (uc *UserController) ChangeEmail(c *gin.Context) {
+	var req models.ChangeEmailRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, "Invalid request data")
+		return
+	}
+
+	// Validate request
+	if errors := utils.ValidateStruct(req); len(errors) > 0 {
+		utils.ValidationErrorResponse(c, errors)
+		return
+	}
+
+	user, ok := loadCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	if !checkCurrentPassword(c, user, req.Password, "Password is incorrect") {
+		return
+	}
+
+	if req.Email == user.Email {
+		utils.ErrorResponse(c, 400, "This is already your email address")
+		return
+	}
+
+	collection := database.GetCollection("users")
+	count, err := collection.CountDocuments(context.TODO(), bson.M{"email": req.Email})
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Database error")
+		return
+	}
+	if count > 0 {
+		utils.ErrorResponse(c, 400, "User with this email already exists")
+		return
+	}
+
+	_, err = collection.UpdateOne(
+		context.TODO(),
+		bson.M{"_id": user.ID},
+		bson.M{"$set": bson.M{"pending_email": req.Email, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to update email")
+		return
+	}
+
+	user.PendingEmail = req.Email
+	if err := sendEmailChangeConfirmation(user); err != nil {
+		utils.ErrorResponse(c, 500, "Failed to send confirmation email")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Please confirm your new email address using the link we sent to it", user.ToResponse())
+}
+
+// ConfirmEmailChange switches to the new email address using the signed link sent to it
+func (uc *UserController) ConfirmEmailChange(c *gin.Context) {
+	tokenString := c.Query("token")
+	if tokenString == "" {
+		utils.ErrorResponse(c, 400, "Confirmation token is required")
+		return
+	}
+
+	claims, err := utils.ParseSignedToken(tokenString, "email_change")
+	if err != nil {
+		utils.ErrorResponse(c, 400, "Invalid or expired confirmation token")
+		return
+	}
+
+	userID, _ := claims["user_id"].(string)
+	newEmail, _ := claims["email"].(string)
+
+	userObjectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil || newEmail == "" {
+		utils.ErrorResponse(c, 400, "Invalid or expired confirmation token")
+		return
+	}
+
+	collection := database.GetCollection("users")
+
+	// The address may have been taken since the change was requested
+	count, err := collection.CountDocuments(context.TODO(), bson.M{"email": newEmail, "_id": bson.M{"$ne": userObjectID}})
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Database error")
+		return
+	}
+	if count > 0 {
+		utils.ErrorResponse(c, 400, "User with this email already exists")
+		return
+	}
+
+	// Only the latest requested address can be confirmed
+	var user models.User
+	err = collection.FindOne(context.TODO(), bson.M{"_id": userObjectID, "pending_email": newEmail}).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			utils.ErrorResponse(c, 400, "Invalid or expired confirmation token")
+		} else {
+			utils.ErrorResponse(c, 500, "Database error")
+		}
+		return
+	}
+
+	oldEmail := user.Email
+	now := time.Now()
+	_, err = collection.UpdateOne(
+		context.TODO(),
+		bson.M{"_id": user.ID},
+		bson.M{
+			"$set":   bson.M{"email": newEmail, "verified_at": now, "updated_at": now},
+			"$unset": bson.M{"pending_email": ""},
+		},
+	)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to update email")
+		return
+	}
+
+	// Let the previous address know, in case the change was not made by its owner
+	mailer.Send(mailer.Message{
+		To:      oldEmail,
+		Subject: "Your email address was changed",
+		Body:    fmt.Sprintf("Hi %s,\n\nThe email address of your account was changed to %s.\n\nIf you did not do this, please contact support immediately.", user.Name, newEmail),
+	})
+
+	user.Email = newEmail
+	user.VerifiedAt = &now
+	user.PendingEmail = ""
+	user.UpdatedAt = now
+
+	// The confirmed address may have pending guest invitations
+	claimInvitations(c, &user)
+
+	utils.SuccessResponse(c, 200, "Email address changed successfully", user.ToResponse())
+}
+
+// DeleteAccount deletes the current user and removes them from every event
+func (uc *UserController) DeleteAccount(c *gin.Context) {
+	var req models.DeleteAccountRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, "Invalid request data")
+		return
+	}
+
+	user, ok := loadCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	if !checkCurrentPassword(c, user, req.Password, "Password is incorrect") {
+		return
+	}
+
+	deletedEvents, err := removeUserFromEvents(user.ID)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to remove user from events")
+		return
+	}
+
+	if err := revokeAllUserTokens(user.ID); err != nil {
+		utils.ErrorResponse(c, 500, "Failed to revoke sessions")
+		return
+	}
+
+	// Remove everything else that belongs to the account
+	for _, name := range []string{"api_keys", "sessions", "refresh_tokens", "password_resets"} {
+		if _, err := database.GetCollection(name).DeleteMany(context.TODO(), bson.M{"user_id": user.ID}); err != nil {
+			utils.ErrorResponse(c, 500, "Failed to delete account data")
+			return
+		}
+	}
+	clearAccountLock(user.Email)
+
+	if _, err := database.GetCollection("users").DeleteOne(context.TODO(), bson.M{"_id": user.ID}); err != nil {
+		utils.ErrorResponse(c, 500, "Failed to delete account")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Account deleted successfully", gin.H{
+		"deleted_events": deletedEvents,
+	})
+}
+
+// removeUserFromEvents cancels and deletes the events the user organizes alone (nobody could manage
+// them anymore), then removes the user from all other events and deletes their event statuses
+func removeUserFromEvents(userID primitive.ObjectID) (int, error) {
+	eventCollection := database.GetCollection("events")
+	eventStatusCollection := database.GetCollection("event_statuses")
+
+	cursor, err := eventCollection.Find(context.TODO(), bson.M{
+		"participants": bson.M{"$elemMatch": bson.M{"user_id": userID, "role": models.RoleOrganizer}},
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(context.TODO())
+
+	var events []models.Event
+	if err = cursor.All(context.TODO(), &events); err != nil {
+		return 0, err
+	}
+
+	var orphanedEvents []models.Event
+	for _, event := range events {
+		var nextOwner primitive.ObjectID
+		for _, p := range event.Participants {
+			if p.UserID != userID && p.Role == models.RoleOrganizer {
+				nextOwner = p.UserID
+				break
+			}
+		}
+		if nextOwner.IsZero() {
+			orphanedEvents = append(orphanedEvents, event)
+			continue
+		}
+
+		// Events the user owns are handed over to the next co-organizer
+		if event.Owner() == userID {
+			if _, err := eventCollection.UpdateOne(context.TODO(), bson.M{"_id": event.ID}, bumpVersion(bson.M{"$set": bson.M{"owner_id": nextOwner}})); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	// Like a deleted event, an orphaned one keeps its answers for the restore window before the purge
+	// job removes it; attendees and guests are told it is cancelled
+	now := time.Now()
+	for i := range orphanedEvents {
+		event := &orphanedEvents[i]
+		notify := event.CancelledAt == nil && event.DeletedAt == nil
+
+		set := bson.M{"updated_at": now}
+		if event.DeletedAt == nil {
+			set["deleted_at"] = now
+		}
+		if notify {
+			set["cancelled_at"] = now
+			set["cancellation_reason"] = "The organizer deleted their account"
+		}
+		if _, err := eventCollection.UpdateOne(context.TODO(), bson.M{"_id": event.ID}, bumpVersion(bson.M{"$set": set})); err != nil {
+			return 0, err
+		}
+
+		if notify {
+			event.CancelledAt = &now
+			event.CancellationReason = set["cancellation_reason"].(string)
+			sendEventCancelled(event, userID)
+		}
+	}
+
+	_, err = eventCollection.UpdateMany(
+		context.TODO(),
+		bson.M{"participants.user_id": userID},
+		bumpVersion(bson.M{
+			"$pull": bson.M{"participants": bson.M{"user_id": userID}},
+			"$set":  bson.M{"updated_at": time.Now()},
+		}),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	// Seats the user held go to the waitlists before their answers are removed
+	releaseUserSeats(userID, nil)
+	if _, err := eventStatusCollection.DeleteMany(context.TODO(), bson.M{"user_id": userID}); err != nil {
+		return 0, err
+	}
+
+	return len(orphanedEvents), nil
+}
+
+// checkCurrentPassword verifies the password before a sensitive change and answers 401 with the
+// message when it is wrong. Accounts created via single sign-on may have none: they must have
+// signed in within REAUTH_MAX_AGE instead, so a stolen access token cannot take them over.
+func checkCurrentPassword(c *gin.Context, user *models.User, password, message string) bool {
+	if user.Password != "" {
+		if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) != nil {
+			utils.ErrorResponse(c, 401, message)
+			return false
+		}
+		return true
+	}
+
+	sessionID, err := primitive.ObjectIDFromHex(c.GetString("session_id"))
+	if err != nil {
+		utils.ErrorResponse(c, 401, "Please sign in again to confirm this change")
+		return false
+	}
+
+	var session models.Session
+	err = database.GetCollection("sessions").FindOne(context.TODO(), bson.M{
+		"_id":        sessionID,
+		"user_id":    user.ID,
+		"revoked_at": nil,
+		"created_at": bson.M{"$gte": time.Now().Add(-config.GetReauthMaxAge())},
+	}).Decode(&session)
+	if err == mongo.ErrNoDocuments {
+		utils.ErrorResponse(c, 401, "Please sign in again to confirm this change")
+		return false
+	}
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Database error")
+		return false
+	}
+	return true
+}
+
+// sendEmailChangeConfirmation emails a signed confirmation link to the pending address
+func sendEmailChangeConfirmation(user *models.User) error {
+	ttl := config.GetEmailVerificationTTL()
+	token, err := utils.GenerateSignedToken("email_change", jwt.MapClaims{
+		"user_id": user.ID.Hex(),
+		"email":   user.PendingEmail,
+	}, ttl)
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/api/v1/email/change/confirm?token=%s", config.GetAppURL(), url.QueryEscape(token))
+	return mailer.Send(mailer.Message{
+		To:      user.PendingEmail,
+		Subject: "Confirm your new email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm that you want to use this address for your account by opening the link below. It expires in %s.\n\n%s",
+			user.Name, ttl, link),
+	})
+}
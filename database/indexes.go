@@ -0,0 +1,119 @@
+package database
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// EnsureIndexes creates the indexes the application relies on (similar to Laravel's migrations)
+func EnsureIndexes() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	indexes := map[string][]mongo.IndexModel{
+		"refresh_tokens": {
+			{Keys: bson.D{{Key: "token_hash", Value: 1}}, Options: options.Index().SetUnique(true)},
+			{Keys: bson.D{{Key: "family_id", Value: 1}}},
+			{Keys: bson.D{{Key: "user_id", Value: 1}}},
+			{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		},
+		"api_keys": {
+			{Keys: bson.D{{Key: "key_hash", Value: 1}}, Options: options.Index().SetUnique(true)},
+			{Keys: bson.D{{Key: "user_id", Value: 1}}},
+		},
+		"login_attempts": {
+			{Keys: bson.D{{Key: "key", Value: 1}}, Options: options.Index().SetUnique(true)},
+			{Keys: bson.D{{Key: "last_failure_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(24 * 60 * 60)},
+		},
+		"audit_logs": {
+			{Keys: bson.D{{Key: "created_at", Value: -1}}},
+			{Keys: bson.D{{Key: "target_user_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		},
+		"oidc_states": {
+			{Keys: bson.D{{Key: "state", Value: 1}}, Options: options.Index().SetUnique(true)},
+			{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		},
+		"users": {
+			{Keys: bson.D{{Key: "identities.provider", Value: 1}, {Key: "identities.subject", Value: 1}}},
+		},
+		"password_resets": {
+			{Keys: bson.D{{Key: "token_hash", Value: 1}}, Options: options.Index().SetUnique(true)},
+			{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		},
+		"sessions": {
+			{Keys: bson.D{{Key: "user_id", Value: 1}}},
+			{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		},
+		"event_statuses": {
+			// Unique: one answer per user and occurrence, so concurrent first answers cannot both take a seat
+			{Keys: bson.D{{Key: "event_id", Value: 1}, {Key: "user_id", Value: 1}, {Key: "occurrence_date", Value: 1}}, Options: options.Index().SetUnique(true)},
+			{Keys: bson.D{{Key: "user_id", Value: 1}}},
+			{Keys: bson.D{{Key: "event_id", Value: 1}, {Key: "status", Value: 1}, {Key: "waitlisted_at", Value: 1}}},
+		},
+		"event_seats": {
+			// Unique: a full event makes the seat upsert fail instead of overbooking
+			{Keys: bson.D{{Key: "event_id", Value: 1}, {Key: "occurrence_date", Value: 1}}, Options: options.Index().SetUnique(true)},
+		},
+		"event_invitations": {
+			{Keys: bson.D{{Key: "event_id", Value: 1}, {Key: "email", Value: 1}}, Options: options.Index().SetUnique(true)},
+			{Keys: bson.D{{Key: "email", Value: 1}}},
+		},
+		"event_history": {
+			// Sparse: answers are recorded without a version
+			{Keys: bson.D{{Key: "event_id", Value: 1}, {Key: "version", Value: 1}}, Options: options.Index().SetUnique(true).SetSparse(true)},
+			{Keys: bson.D{{Key: "event_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		},
+		"event_invite_links": {
+			{Keys: bson.D{{Key: "token_hash", Value: 1}}, Options: options.Index().SetUnique(true)},
+			{Keys: bson.D{{Key: "event_id", Value: 1}}},
+		},
+		"events": {
+			{Keys: bson.D{{Key: "series_id", Value: 1}}},
+			{Keys: bson.D{{Key: "start_at", Value: 1}}},
+			{Keys: bson.D{{Key: "visibility", Value: 1}, {Key: "start_at", Value: 1}}},
+			{Keys: bson.D{{Key: "deleted_at", Value: 1}}, Options: options.Index().SetSparse(true)},
+		},
+		"revoked_tokens": {
+			{Keys: bson.D{{Key: "jti", Value: 1}}, Options: options.Index().SetUnique(true)},
+			{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		},
+	}
+
+	// Indexes whose options changed after they were first created have to be dropped before they can be recreated
+	dropOutdatedIndex(ctx, "event_statuses", "event_id_1_user_id_1_occurrence_date_1", func(spec *mongo.IndexSpecification) bool {
+		return spec.Unique != nil && *spec.Unique
+	})
+	dropOutdatedIndex(ctx, "event_history", "event_id_1_version_1", func(spec *mongo.IndexSpecification) bool {
+		return spec.Sparse != nil && *spec.Sparse
+	})
+
+	for name, models := range indexes {
+		if _, err := GetCollection(name).Indexes().CreateMany(ctx, models); err != nil {
+			log.Printf("Failed to create indexes for %s: %v", name, err)
+		}
+	}
+}
+
+// dropOutdatedIndex drops an index that exists without the options it has now (for a unique index,
+// duplicates left in the collection make it fail to build, which is logged by EnsureIndexes)
+func dropOutdatedIndex(ctx context.Context, collection, name string, upToDate func(spec *mongo.IndexSpecification) bool) {
+	specs, err := GetCollection(collection).Indexes().ListSpecifications(ctx)
+	if err != nil {
+		log.Printf("Failed to list indexes of %s: %v", collection, err)
+		return
+	}
+
+	for _, spec := range specs {
+		if spec.Name != name || upToDate(spec) {
+			continue
+		}
+		if _, err := GetCollection(collection).Indexes().DropOne(ctx, name); err != nil {
+			log.Printf("Failed to drop index %s of %s: %v", name, collection, err)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"tools-backend/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// EventTimesOptions configures the conversion of the old date and time strings of events
+type EventTimesOptions struct {
+	Timezone        *time.Location // Timezone the old strings were written in (also stored on the events)
+	DefaultDuration time.Duration  // Length given to converted events, the old format had no end time
+	DryRun          bool           // Report what would change without writing
+}
+
+// EventTimesResult summarizes a run of ConvertEventTimes
+type EventTimesResult struct {
+	Converted int
+	Skipped   int
+	Warnings  []string
+}
+
+// legacyTimeLayouts are the time formats found in the old free-form "time" field
+var legacyTimeLayouts = []string{"15:04", "15:04:05", "3:04PM", "3:04 PM", "3PM", "3 PM", "15.04", "15h04"}
+
+// ConvertEventTimes replaces the "date" (YYYY-MM-DD) and "time" strings of events with UTC
+// start_at/end_at timestamps and an IANA timezone (similar to a Laravel migration).
+// Events that already have start_at are left alone, so the migration can be run again.
+func ConvertEventTimes(ctx context.Context, opts EventTimesOptions) (EventTimesResult, error) {
+	var result EventTimesResult
+	collection := database.GetCollection("events")
+
+	cursor, err := collection.Find(ctx, bson.M{"start_at": bson.M{"$exists": false}, "date": bson.M{"$exists": true}})
+	if err != nil {
+		return result, err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			return result, err
+		}
+		id := doc["_id"]
+
+		date, _ := doc["date"].(string)
+		clock, _ := doc["time"].(string)
+		startAt, warning, err := legacyStart(date, clock, opts.Timezone)
+		if err != nil {
+			result.Skipped++
+			result.Warnings = append(result.Warnings, fmt.Sprintf("event %v skipped: %v", id, err))
+			continue
+		}
+		if warning != "" {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("event %v: %s", id, warning))
+		}
+
+		set := bson.M{
+			"start_at": startAt,
+			"end_at":   startAt.Add(opts.DefaultDuration),
+			"timezone": opts.Timezone.String(),
+		}
+
+		// Occurrence overrides of recurring events used the same strings
+		if overrides, ok := doc["overrides"].(bson.A); ok {
+			set["overrides"] = convertOverrides(overrides, clock, opts)
+		}
+
+		result.Converted++
+		if opts.DryRun {
+			continue
+		}
+
+		_, err = collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+			"$set":   set,
+			"$unset": bson.M{"date": "", "time": ""},
+		})
+		if err != nil {
+			return result, err
+		}
+	}
+
+	return result, cursor.Err()
+}
+
+// convertOverrides moves the date and time strings of occurrence overrides to start_at/end_at
+func convertOverrides(overrides bson.A, eventClock string, opts EventTimesOptions) bson.A {
+	converted := make(bson.A, 0, len(overrides))
+	for _, item := range overrides {
+		override, ok := item.(bson.M)
+		if !ok {
+			converted = append(converted, item)
+			continue
+		}
+
+		date, hasDate := override["date"].(string)
+		clock, hasClock := override["time"].(string)
+		delete(override, "date")
+		delete(override, "time")
+
+		if hasDate || hasClock {
+			if !hasDate {
+				date, _ = override["occurrence_date"].(string)
+			}
+			if !hasClock {
+				clock = eventClock
+			}
+			if startAt, _, err := legacyStart(date, clock, opts.Timezone); err == nil {
+				override["start_at"] = startAt
+				override["end_at"] = startAt.Add(opts.DefaultDuration)
+			}
+		}
+		converted = append(converted, override)
+	}
+	return converted
+}
+
+// legacyStart reads an old date and time in the given timezone. A time that cannot be read
+// falls back to midnight with a warning; a date that cannot be read is an error.
+func legacyStart(date, clock string, loc *time.Location) (time.Time, string, error) {
+	day, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(date), loc)
+	if err != nil {
+		return time.Time{}, "", fmt.Errorf("invalid date %q", date)
+	}
+
+	clock = strings.ToUpper(strings.TrimSpace(clock))
+	for _, layout := range legacyTimeLayouts {
+		if parsed, err := time.Parse(layout, clock); err == nil {
+			start := time.Date(day.Year(), day.Month(), day.Day(), parsed.Hour(), parsed.Minute(), parsed.Second(), 0, loc)
+			return start.UTC(), "", nil
+		}
+	}
+
+	return day.UTC(), fmt.Sprintf("time %q not understood, using 00:00", clock), nil
+}
@@ -0,0 +1,292 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ErrTestFailed is returned when a "test" operation does not match the document
+var ErrTestFailed = errors.New("test failed")
+
+// Operation is one RFC 6902 JSON Patch operation
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"` // Required by add, replace and test (null is a value)
+}
+
+// value decodes the value of an operation
+func (o Operation) value() (interface{}, error) {
+	if len(o.Value) == 0 {
+		return nil, errors.New("value is required")
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(o.Value, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// Apply applies the operations of an RFC 6902 JSON Patch in order to a decoded JSON document.
+// The patch is atomic: on an error the original document is left untouched.
+func Apply(document interface{}, operations []Operation) (interface{}, error) {
+	result := deepCopy(document)
+	for i, operation := range operations {
+		var err error
+		result, err = applyOperation(result, operation)
+		if err != nil {
+			return nil, fmt.Errorf("operation %d (%s %s): %w", i, operation.Op, operation.Path, err)
+		}
+	}
+	return result, nil
+}
+
+// applyOperation applies one operation, returning the new document
+func applyOperation(document interface{}, operation Operation) (interface{}, error) {
+	switch operation.Op {
+	case "add":
+		value, err := operation.value()
+		if err != nil {
+			return nil, err
+		}
+		return add(document, operation.Path, value)
+	case "remove":
+		document, _, err := remove(document, operation.Path)
+		return document, err
+	case "replace":
+		value, err := operation.value()
+		if err != nil {
+			return nil, err
+		}
+		document, _, err := remove(document, operation.Path)
+		if err != nil {
+			return nil, err
+		}
+		return add(document, operation.Path, value)
+	case "move":
+		if strings.HasPrefix(operation.Path, operation.From+"/") {
+			return nil, errors.New("cannot move a value into itself")
+		}
+		document, value, err := remove(document, operation.From)
+		if err != nil {
+			return nil, err
+		}
+		return add(document, operation.Path, value)
+	case "copy":
+		value, err := get(document, operation.From)
+		if err != nil {
+			return nil, err
+		}
+		return add(document, operation.Path, deepCopy(value))
+	case "test":
+		expected, err := operation.value()
+		if err != nil {
+			return nil, err
+		}
+		value, err := get(document, operation.Path)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(value, expected) {
+			return nil, ErrTestFailed
+		}
+		return document, nil
+	default:
+		return nil, errors.New("unknown operation")
+	}
+}
+
+// parsePointer splits an RFC 6901 JSON pointer into its unescaped reference tokens
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, errors.New("path must start with /")
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// arrayIndex reads an array index token; "-" (past the end) is only allowed when adding
+func arrayIndex(token string, length int, adding bool) (int, error) {
+	if token == "-" && adding {
+		return length, nil
+	}
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	limit := length - 1
+	if adding {
+		limit = length
+	}
+	if index > limit {
+		return 0, fmt.Errorf("array index %d out of range", index)
+	}
+	return index, nil
+}
+
+// get returns the value a pointer refers to
+func get(document interface{}, pointer string) (interface{}, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	current := document
+	for _, token := range tokens {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("path %s does not exist", pointer)
+			}
+			current = value
+		case []interface{}:
+			index, err := arrayIndex(token, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			current = node[index]
+		default:
+			return nil, fmt.Errorf("path %s does not exist", pointer)
+		}
+	}
+	return current, nil
+}
+
+// add sets a member of an object or inserts into an array, returning the new document
+func add(document interface{}, pointer string, value interface{}) (interface{}, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	return update(document, tokens, pointer, func(parent interface{}, token string) (interface{}, error) {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			node[token] = value
+			return node, nil
+		case []interface{}:
+			index, err := arrayIndex(token, len(node), true)
+			if err != nil {
+				return nil, err
+			}
+			node = append(node, nil)
+			copy(node[index+1:], node[index:])
+			node[index] = value
+			return node, nil
+		default:
+			return nil, fmt.Errorf("path %s does not exist", pointer)
+		}
+	})
+}
+
+// remove deletes a member of an object or an array element, returning the new document and
+// the removed value
+func remove(document interface{}, pointer string) (interface{}, interface{}, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, document, nil
+	}
+
+	var removed interface{}
+	result, err := update(document, tokens, pointer, func(parent interface{}, token string) (interface{}, error) {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			value, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("path %s does not exist", pointer)
+			}
+			removed = value
+			delete(node, token)
+			return node, nil
+		case []interface{}:
+			index, err := arrayIndex(token, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			removed = node[index]
+			return append(node[:index], node[index+1:]...), nil
+		default:
+			return nil, fmt.Errorf("path %s does not exist", pointer)
+		}
+	})
+	return result, removed, err
+}
+
+// update walks to the parent of the last token and replaces it with what change returns
+// (arrays change length, so every level stores the node it gets back)
+func update(node interface{}, tokens []string, pointer string, change func(parent interface{}, token string) (interface{}, error)) (interface{}, error) {
+	if len(tokens) == 1 {
+		return change(node, tokens[0])
+	}
+
+	token := tokens[0]
+	switch parent := node.(type) {
+	case map[string]interface{}:
+		child, ok := parent[token]
+		if !ok {
+			return nil, fmt.Errorf("path %s does not exist", pointer)
+		}
+		updated, err := update(child, tokens[1:], pointer, change)
+		if err != nil {
+			return nil, err
+		}
+		parent[token] = updated
+		return parent, nil
+	case []interface{}:
+		index, err := arrayIndex(token, len(parent), false)
+		if err != nil {
+			return nil, err
+		}
+		updated, err := update(parent[index], tokens[1:], pointer, change)
+		if err != nil {
+			return nil, err
+		}
+		parent[index] = updated
+		return parent, nil
+	default:
+		return nil, fmt.Errorf("path %s does not exist", pointer)
+	}
+}
+
+// deepCopy copies a decoded JSON value so patches never change shared maps and slices
+func deepCopy(value interface{}) interface{} {
+	switch node := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(node))
+		for key, child := range node {
+			copied[key] = deepCopy(child)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(node))
+		for i, child := range node {
+			copied[i] = deepCopy(child)
+		}
+		return copied
+	default:
+		return value
+	}
+}
@@ -0,0 +1,257 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func decode(t *testing.T, data string) interface{} {
+	t.Helper()
+	var value interface{}
+	if err := json.Unmarshal([]byte(data), &value); err != nil {
+		t.Fatalf("invalid JSON %s: %v", data, err)
+	}
+	return value
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		patch    string
+		want     string // Empty when the patch must fail
+		testFail bool   // The failure must be ErrTestFailed
+	}{
+		// RFC 6902, Appendix A
+		{
+			name:     "A.1 adding an object member",
+			document: `{"foo": "bar"}`,
+			patch:    `[{"op": "add", "path": "/baz", "value": "qux"}]`,
+			want:     `{"baz": "qux", "foo": "bar"}`,
+		},
+		{
+			name:     "A.2 adding an array element",
+			document: `{"foo": ["bar", "baz"]}`,
+			patch:    `[{"op": "add", "path": "/foo/1", "value": "qux"}]`,
+			want:     `{"foo": ["bar", "qux", "baz"]}`,
+		},
+		{
+			name:     "A.3 removing an object member",
+			document: `{"baz": "qux", "foo": "bar"}`,
+			patch:    `[{"op": "remove", "path": "/baz"}]`,
+			want:     `{"foo": "bar"}`,
+		},
+		{
+			name:     "A.4 removing an array element",
+			document: `{"foo": ["bar", "qux", "baz"]}`,
+			patch:    `[{"op": "remove", "path": "/foo/1"}]`,
+			want:     `{"foo": ["bar", "baz"]}`,
+		},
+		{
+			name:     "A.5 replacing a value",
+			document: `{"baz": "qux", "foo": "bar"}`,
+			patch:    `[{"op": "replace", "path": "/baz", "value": "boo"}]`,
+			want:     `{"baz": "boo", "foo": "bar"}`,
+		},
+		{
+			name:     "A.6 moving a value",
+			document: `{"foo": {"bar": "baz", "waldo": "fred"}, "qux": {"corge": "grault"}}`,
+			patch:    `[{"op": "move", "from": "/foo/waldo", "path": "/qux/thud"}]`,
+			want:     `{"foo": {"bar": "baz"}, "qux": {"corge": "grault", "thud": "fred"}}`,
+		},
+		{
+			name:     "A.7 moving an array element",
+			document: `{"foo": ["all", "grass", "cows", "eat"]}`,
+			patch:    `[{"op": "move", "from": "/foo/1", "path": "/foo/3"}]`,
+			want:     `{"foo": ["all", "cows", "eat", "grass"]}`,
+		},
+		{
+			name:     "A.8 testing a value: success",
+			document: `{"baz": "qux", "foo": ["a", 2, "c"]}`,
+			patch:    `[{"op": "test", "path": "/baz", "value": "qux"}, {"op": "test", "path": "/foo/1", "value": 2}]`,
+			want:     `{"baz": "qux", "foo": ["a", 2, "c"]}`,
+		},
+		{
+			name:     "A.9 testing a value: error",
+			document: `{"baz": "qux"}`,
+			patch:    `[{"op": "test", "path": "/baz", "value": "bar"}]`,
+			testFail: true,
+		},
+		{
+			name:     "A.10 adding a nested member object",
+			document: `{"foo": "bar"}`,
+			patch:    `[{"op": "add", "path": "/child", "value": {"grandchild": {}}}]`,
+			want:     `{"foo": "bar", "child": {"grandchild": {}}}`,
+		},
+		{
+			name:     "A.11 ignoring unrecognized elements",
+			document: `{"foo": "bar"}`,
+			patch:    `[{"op": "add", "path": "/baz", "value": "qux", "xyz": 123}]`,
+			want:     `{"foo": "bar", "baz": "qux"}`,
+		},
+		{
+			name:     "A.12 adding to a nonexistent target",
+			document: `{"foo": "bar"}`,
+			patch:    `[{"op": "add", "path": "/baz/bat", "value": "qux"}]`,
+		},
+		{
+			name:     "A.14 ~ escape ordering",
+			document: `{"/": 9, "~1": 10}`,
+			patch:    `[{"op": "test", "path": "/~01", "value": 10}]`,
+			want:     `{"/": 9, "~1": 10}`,
+		},
+		{
+			name:     "A.15 comparing strings and numbers",
+			document: `{"/": 9, "~1": 10}`,
+			patch:    `[{"op": "test", "path": "/~01", "value": "10"}]`,
+			testFail: true,
+		},
+		{
+			name:     "A.16 adding an array value",
+			document: `{"foo": ["bar"]}`,
+			patch:    `[{"op": "add", "path": "/foo/-", "value": ["abc", "def"]}]`,
+			want:     `{"foo": ["bar", ["abc", "def"]]}`,
+		},
+
+		// Escaped pointers
+		{
+			name:     "escaped slash",
+			document: `{"a/b": 1}`,
+			patch:    `[{"op": "replace", "path": "/a~1b", "value": 2}]`,
+			want:     `{"a/b": 2}`,
+		},
+		{
+			name:     "escaped tilde",
+			document: `{"m~n": 1}`,
+			patch:    `[{"op": "remove", "path": "/m~0n"}]`,
+			want:     `{}`,
+		},
+		{
+			name:     "empty key",
+			document: `{"": 0}`,
+			patch:    `[{"op": "copy", "from": "/", "path": "/zero"}]`,
+			want:     `{"": 0, "zero": 0}`,
+		},
+
+		// "-" on arrays
+		{
+			name:     "- appends to an empty array",
+			document: `{"tags": []}`,
+			patch:    `[{"op": "add", "path": "/tags/-", "value": "a"}, {"op": "add", "path": "/tags/-", "value": "b"}]`,
+			want:     `{"tags": ["a", "b"]}`,
+		},
+		{
+			name:     "- cannot be removed",
+			document: `{"tags": ["a"]}`,
+			patch:    `[{"op": "remove", "path": "/tags/-"}]`,
+		},
+		{
+			name:     "- cannot be replaced",
+			document: `{"tags": ["a"]}`,
+			patch:    `[{"op": "replace", "path": "/tags/-", "value": "b"}]`,
+		},
+		{
+			name:     "index past the end",
+			document: `{"tags": ["a"]}`,
+			patch:    `[{"op": "add", "path": "/tags/2", "value": "b"}]`,
+		},
+		{
+			name:     "index with a leading zero",
+			document: `{"tags": ["a", "b"]}`,
+			patch:    `[{"op": "remove", "path": "/tags/01"}]`,
+		},
+
+		// Moves and copies
+		{
+			name:     "move into a child of itself",
+			document: `{"foo": {"bar": 1}}`,
+			patch:    `[{"op": "move", "from": "/foo", "path": "/foo/bar/baz"}]`,
+		},
+		{
+			name:     "move to a sibling with the same prefix",
+			document: `{"foo": 1}`,
+			patch:    `[{"op": "move", "from": "/foo", "path": "/foobar"}]`,
+			want:     `{"foobar": 1}`,
+		},
+		{
+			name:     "copy is independent of its source",
+			document: `{"a": {"b": 1}}`,
+			patch:    `[{"op": "copy", "from": "/a", "path": "/c"}, {"op": "replace", "path": "/c/b", "value": 2}]`,
+			want:     `{"a": {"b": 1}, "c": {"b": 2}}`,
+		},
+
+		// Values and paths
+		{
+			name:     "null is a value",
+			document: `{"a": 1}`,
+			patch:    `[{"op": "replace", "path": "/a", "value": null}, {"op": "test", "path": "/a", "value": null}]`,
+			want:     `{"a": null}`,
+		},
+		{
+			name:     "missing value",
+			document: `{"a": 1}`,
+			patch:    `[{"op": "add", "path": "/b"}]`,
+		},
+		{
+			name:     "replacing a missing member",
+			document: `{"a": 1}`,
+			patch:    `[{"op": "replace", "path": "/b", "value": 2}]`,
+		},
+		{
+			name:     "replacing the whole document",
+			document: `{"a": 1}`,
+			patch:    `[{"op": "replace", "path": "", "value": {"b": 2}}]`,
+			want:     `{"b": 2}`,
+		},
+		{
+			name:     "path without a leading slash",
+			document: `{"a": 1}`,
+			patch:    `[{"op": "remove", "path": "a"}]`,
+		},
+		{
+			name:     "unknown operation",
+			document: `{"a": 1}`,
+			patch:    `[{"op": "increment", "path": "/a"}]`,
+		},
+		{
+			name:     "a failing test after changes",
+			document: `{"a": 1}`,
+			patch:    `[{"op": "replace", "path": "/a", "value": 2}, {"op": "test", "path": "/a", "value": 1}]`,
+			testFail: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var operations []Operation
+			if err := json.Unmarshal([]byte(tt.patch), &operations); err != nil {
+				t.Fatalf("invalid patch: %v", err)
+			}
+			document := decode(t, tt.document)
+
+			got, err := Apply(document, operations)
+			if tt.want == "" {
+				if err == nil {
+					t.Fatalf("Apply succeeded with %v", got)
+				}
+				if tt.testFail != errors.Is(err, ErrTestFailed) {
+					t.Errorf("errors.Is(%v, ErrTestFailed) = %v, want %v", err, !tt.testFail, tt.testFail)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Apply: %v", err)
+				}
+				if want := decode(t, tt.want); !reflect.DeepEqual(got, want) {
+					t.Errorf("Apply = %v, want %v", got, want)
+				}
+			}
+
+			// Patches are atomic and never change the document they are given
+			if original := decode(t, tt.document); !reflect.DeepEqual(document, original) {
+				t.Errorf("the document was changed to %v", document)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package mailer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+	"tools-backend/config"
+)
+
+// FileMailer writes each email as a .eml file into a directory (for local development)
+type FileMailer struct {
+	Dir string
+}
+
+// Send writes the message to a new file in Dir
+func (m *FileMailer) Send(msg Message) error {
+	if err := os.MkdirAll(m.Dir, 0o755); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%d.eml", time.Now().UnixNano())
+	content := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nDate: %s\r\n\r\n%s\r\n",
+		config.GetMailFrom(),
+		msg.To,
+		msg.Subject,
+		time.Now().Format(time.RFC1123Z),
+		msg.Body,
+	)
+
+	return os.WriteFile(filepath.Join(m.Dir, name), []byte(content), 0o644)
+}
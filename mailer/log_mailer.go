@@ -0,0 +1,14 @@
+package mailer
+
+import (
+	"log"
+)
+
+// LogMailer writes emails to the application log (for local development)
+type LogMailer struct{}
+
+// Send logs the message instead of delivering it
+func (m *LogMailer) Send(msg Message) error {
+	log.Printf("[mail] To: %s | Subject: %s\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
@@ -0,0 +1,53 @@
+package mailer
+
+import (
+	"log"
+	"tools-backend/config"
+)
+
+// Message represents an outgoing email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends emails (similar to Laravel's mail drivers)
+type Mailer interface {
+	Send(msg Message) error
+}
+
+var current Mailer
+
+// Init selects the mailer from the MAIL_DRIVER setting (log, file or smtp)
+func Init() {
+	switch driver := config.GetMailDriver(); driver {
+	case "file":
+		current = &FileMailer{Dir: config.GetEnv("MAIL_FILE_DIR", "storage/mail")}
+	case "smtp":
+		current = &SMTPMailer{
+			Host:     config.GetEnv("SMTP_HOST", "localhost"),
+			Port:     config.GetEnv("SMTP_PORT", "587"),
+			Username: config.GetEnv("SMTP_USERNAME", ""),
+			Password: config.GetEnv("SMTP_PASSWORD", ""),
+		}
+	default:
+		if driver != "log" {
+			log.Printf("Unknown MAIL_DRIVER %q, falling back to log", driver)
+		}
+		current = &LogMailer{}
+	}
+}
+
+// SetMailer replaces the active mailer (e.g. with a custom driver)
+func SetMailer(m Mailer) {
+	current = m
+}
+
+// Send sends a message with the active mailer (similar to Laravel's Mail::send())
+func Send(msg Message) error {
+	if current == nil {
+		Init()
+	}
+	return current.Send(msg)
+}
@@ -0,0 +1,34 @@
+package mailer
+
+import (
+	"fmt"
+	"net"
+	"net/smtp"
+	"tools-backend/config"
+)
+
+// SMTPMailer delivers emails through an SMTP server
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+}
+
+// Send delivers the message using plain SMTP auth (when credentials are configured)
+func (m *SMTPMailer) Send(msg Message) error {
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+
+	from := config.GetMailFrom()
+	content := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s\r\n",
+		from,
+		msg.To,
+		msg.Subject,
+		msg.Body,
+	)
+
+	return smtp.SendMail(net.JoinHostPort(m.Host, m.Port), auth, from, []string{msg.To}, []byte(content))
+}
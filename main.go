@@ -3,17 +3,37 @@ package main
 import (
 	"log"
 	"tools-backend/config"
+	"tools-backend/controllers"
 	"tools-backend/database"
+	"tools-backend/mailer"
 	"tools-backend/routes"
+	"tools-backend/utils"
 )
 
 func main() {
 	// Load environment variables (similar to Laravel's .env)
 	config.LoadEnv()
 
+	// Load JWT signing keys
+	if err := utils.LoadSigningKeys(); err != nil {
+		log.Fatal("Failed to load JWT signing keys:", err)
+	}
+
 	// Connect to MongoDB (similar to Laravel's database connection)
 	database.Connect()
 
+	// Create indexes (similar to Laravel's php artisan migrate)
+	database.EnsureIndexes()
+
+	// Promote the accounts listed in ADMIN_EMAILS
+	controllers.EnsureConfiguredAdmins()
+
+	// Select the mail driver (similar to Laravel's MAIL_MAILER)
+	mailer.Init()
+
+	// Purge deleted events past their restore window (similar to Laravel's scheduler)
+	controllers.StartEventPurge()
+
 	// Setup routes (similar to Laravel's routes/web.php)
 	router := routes.SetupRoutes()
 
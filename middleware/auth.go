@@ -1,16 +1,26 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"strings"
-	"tools-backend/config"
+	"time"
+	"tools-backend/database"
+	"tools-backend/models"
 	"tools-backend/utils"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// sessionActivityInterval is how often a session's last_seen_at is refreshed
+const sessionActivityInterval = time.Minute
+
 // Auth middleware (similar to Laravel's auth middleware)
+// Accepts "Bearer <access token>" and "ApiKey <personal API key>"
 func Auth() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
@@ -20,31 +30,235 @@ func Auth() gin.HandlerFunc {
 			return
 		}
 
-		// Extract token from "Bearer <token>"
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		if tokenString == authHeader {
+		switch {
+		case strings.HasPrefix(authHeader, "Bearer "):
+			authenticateToken(c, strings.TrimPrefix(authHeader, "Bearer "))
+		case strings.HasPrefix(authHeader, "ApiKey "):
+			authenticateAPIKey(c, strings.TrimPrefix(authHeader, "ApiKey "))
+		default:
 			utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid authorization header format")
 			c.Abort()
 			return
 		}
 
-		// Parse and validate token
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			return []byte(config.GetJWTSecret()), nil
-		})
+		if c.IsAborted() {
+			return
+		}
+
+		// Requests made while impersonating are always marked and audited (Destructive routes refuse them)
+		if c.GetString("impersonator_id") != "" {
+			defer recordImpersonatedRequest(c)
+			c.Header("X-Impersonated-By", c.GetString("impersonator_id"))
+		}
+
+		c.Next()
+	}
+}
 
-		if err != nil || !token.Valid {
-			utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid token")
+// RejectAPIKeys keeps API keys away from account security routes (logout, 2FA, key management)
+func RejectAPIKeys() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("auth_method") == "api_key" {
+			utils.ErrorResponse(c, http.StatusForbidden, "This endpoint cannot be used with an API key")
 			c.Abort()
 			return
 		}
 
-		// Extract claims
-		if claims, ok := token.Claims.(jwt.MapClaims); ok {
-			c.Set("user_id", claims["user_id"])
-			c.Set("user_email", claims["email"])
+		c.Next()
+	}
+}
+
+// authenticateToken validates a JWT access token
+func authenticateToken(c *gin.Context, tokenString string) {
+	// Parse and validate token
+	claims, err := utils.ParseJWT(tokenString)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid token")
+		c.Abort()
+		return
+	}
+
+	// Only access tokens carrying a jti can be used (and revoked)
+	jti, _ := claims["jti"].(string)
+	if jti == "" || claims["typ"] != "access" {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid token")
+		c.Abort()
+		return
+	}
+
+	// Check the deny-list (logout, logout-all, refresh token reuse)
+	revoked, err := database.GetCollection("revoked_tokens").CountDocuments(context.TODO(), bson.M{"jti": jti})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to verify token")
+		c.Abort()
+		return
+	}
+	if revoked > 0 {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Token has been revoked")
+		c.Abort()
+		return
+	}
+
+	// Tokens bound to a session stop working as soon as the session is revoked
+	if sid, _ := claims["sid"].(string); sid != "" {
+		if !checkSession(c, sid) {
+			return
 		}
+		c.Set("session_id", sid)
+	}
 
-		c.Next()
+	// Impersonation tokens name the admin acting as the user in the "act" claim (RFC 8693)
+	impersonatorID := ""
+	if act, ok := claims["act"].(map[string]interface{}); ok {
+		impersonatorID, _ = act["sub"].(string)
+		if !checkImpersonator(c, impersonatorID) {
+			return
+		}
+	}
+
+	// Role and suspension are read from the database so changes apply immediately
+	userID, _ := claims["user_id"].(string)
+	user, ok := loadAuthUser(c, userID)
+	if !ok {
+		return
+	}
+
+	// Extract claims
+	c.Set("user_id", claims["user_id"])
+	c.Set("user_email", claims["email"])
+	c.Set("auth_method", "token")
+	c.Set("token_jti", jti)
+	c.Set("email_verified", claims["email_verified"] == true)
+	if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+		c.Set("token_exp", exp.Time)
+	}
+	c.Set("user_role", string(user.EffectiveRole()))
+	c.Set("user_timezone", user.Timezone)
+	if impersonatorID != "" {
+		c.Set("impersonator_id", impersonatorID)
 	}
 }
+
+// loadAuthUser fetches the fields of the authenticated user the middleware needs
+func loadAuthUser(c *gin.Context, userID string) (*models.User, bool) {
+	userObjectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid token")
+		c.Abort()
+		return nil, false
+	}
+
+	var user models.User
+	err = database.GetCollection("users").FindOne(
+		context.TODO(),
+		bson.M{"_id": userObjectID},
+		options.FindOne().SetProjection(bson.M{"email": 1, "verified_at": 1, "role": 1, "suspended_at": 1, "timezone": 1}),
+	).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			utils.ErrorResponse(c, http.StatusUnauthorized, "User no longer exists")
+		} else {
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to verify user")
+		}
+		c.Abort()
+		return nil, false
+	}
+
+	// Suspending an account immediately locks out every token and API key it has
+	if user.SuspendedAt != nil {
+		utils.ErrorResponse(c, http.StatusForbidden, "Account has been suspended")
+		c.Abort()
+		return nil, false
+	}
+
+	return &user, true
+}
+
+// checkSession rejects revoked sessions and records activity on active ones
+func checkSession(c *gin.Context, sid string) bool {
+	sessionID, err := primitive.ObjectIDFromHex(sid)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid token")
+		c.Abort()
+		return false
+	}
+
+	collection := database.GetCollection("sessions")
+	var session models.Session
+	err = collection.FindOne(context.TODO(), bson.M{"_id": sessionID}).Decode(&session)
+	if err != nil && err != mongo.ErrNoDocuments {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to verify session")
+		c.Abort()
+		return false
+	}
+	if err == mongo.ErrNoDocuments || session.RevokedAt != nil {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Session has been revoked")
+		c.Abort()
+		return false
+	}
+
+	// Only write last_seen_at about once a minute
+	now := time.Now()
+	if now.Sub(session.LastSeenAt) > sessionActivityInterval {
+		collection.UpdateOne(
+			context.TODO(),
+			bson.M{"_id": sessionID, "last_seen_at": bson.M{"$lt": now.Add(-sessionActivityInterval)}},
+			bson.M{"$set": bson.M{"last_seen_at": now, "ip": c.ClientIP()}},
+		)
+	}
+	return true
+}
+
+// authenticateAPIKey validates a personal API key and checks its scope for the request method
+func authenticateAPIKey(c *gin.Context, rawKey string) {
+	var apiKey models.APIKey
+	err := database.GetCollection("api_keys").FindOne(context.TODO(), bson.M{
+		"key_hash":   utils.HashToken(rawKey),
+		"revoked_at": nil,
+	}).Decode(&apiKey)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid API key")
+		} else {
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to verify API key")
+		}
+		c.Abort()
+		return
+	}
+
+	if apiKey.ExpiresAt != nil && apiKey.ExpiresAt.Before(time.Now()) {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "API key has expired")
+		c.Abort()
+		return
+	}
+
+	requiredScope := models.ScopeWrite
+	if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+		requiredScope = models.ScopeRead
+	}
+	if !apiKey.HasScope(requiredScope) {
+		utils.ErrorResponse(c, http.StatusForbidden, "API key is missing the "+requiredScope+" scope")
+		c.Abort()
+		return
+	}
+
+	user, ok := loadAuthUser(c, apiKey.UserID.Hex())
+	if !ok {
+		return
+	}
+
+	// Track usage; a failure here should not block the request
+	database.GetCollection("api_keys").UpdateOne(
+		context.TODO(),
+		bson.M{"_id": apiKey.ID},
+		bson.M{"$set": bson.M{"last_used_at": time.Now(), "last_used_ip": c.ClientIP()}},
+	)
+
+	c.Set("user_id", apiKey.UserID.Hex())
+	c.Set("user_email", user.Email)
+	c.Set("auth_method", "api_key")
+	c.Set("api_key_id", apiKey.ID.Hex())
+	c.Set("email_verified", user.VerifiedAt != nil)
+	c.Set("user_role", string(user.EffectiveRole()))
+	c.Set("user_timezone", user.Timezone)
+}
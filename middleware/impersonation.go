@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"net/http"
+	"tools-backend/config"
+	"tools-backend/models"
+	"tools-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// NoImpersonation keeps impersonation tokens away from sensitive routes (credentials, 2FA, admin)
+func NoImpersonation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("impersonator_id") != "" {
+			utils.ErrorResponse(c, http.StatusForbidden, "This endpoint cannot be used while impersonating")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// checkImpersonator makes sure the admin named in an impersonation token is still an active admin
+func checkImpersonator(c *gin.Context, impersonatorID string) bool {
+	impersonator, ok := loadAuthUser(c, impersonatorID)
+	if !ok {
+		return false
+	}
+
+	if impersonator.EffectiveRole() != models.PlatformRoleAdmin {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Impersonation is no longer allowed")
+		c.Abort()
+		return false
+	}
+	return true
+}
+
+// Destructive marks a route that deletes data, cannot be undone or notifies other users;
+// impersonation tokens are refused on it unless IMPERSONATION_ALLOW_DELETE is on
+func Destructive() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("impersonator_id") != "" && !config.GetImpersonationAllowDelete() {
+			utils.ErrorResponse(c, http.StatusForbidden, "Destructive actions are not allowed while impersonating")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// recordImpersonatedRequest writes an audit entry for a request made with an impersonation token
+func recordImpersonatedRequest(c *gin.Context) {
+	entry := models.AuditLog{
+		Action:    models.AuditImpersonatedRequest,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Details: map[string]interface{}{
+			"method": c.Request.Method,
+			"path":   c.Request.URL.Path,
+			"status": c.Writer.Status(),
+		},
+	}
+	if actorID, err := primitive.ObjectIDFromHex(c.GetString("impersonator_id")); err == nil {
+		entry.ActorID = &actorID
+	}
+	if targetID, err := primitive.ObjectIDFromHex(c.GetString("user_id")); err == nil {
+		entry.TargetUserID = &targetID
+	}
+
+	utils.RecordAudit(entry)
+}
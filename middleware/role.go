@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"net/http"
+	"tools-backend/models"
+	"tools-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole only lets users with one of the given platform roles through (must run after Auth)
+func RequireRole(roles ...models.PlatformRole) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		current := models.PlatformRole(c.GetString("user_role"))
+		for _, role := range roles {
+			if current == role {
+				c.Next()
+				return
+			}
+		}
+
+		utils.ErrorResponse(c, http.StatusForbidden, "You do not have permission to access this resource")
+		c.Abort()
+	}
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"net/http"
+	"tools-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ViewerTimezone picks the timezone event times are rendered in: ?tz=, the X-Timezone header,
+// then the user's profile timezone. Without any, events are shown in their own timezone.
+func ViewerTimezone() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := c.Query("tz")
+		if name == "" {
+			name = c.GetHeader("X-Timezone")
+		}
+
+		if name != "" {
+			loc, err := utils.LoadTimezone(name)
+			if err != nil {
+				utils.ErrorResponse(c, http.StatusBadRequest, "Invalid timezone. Use an IANA name such as Europe/Paris")
+				c.Abort()
+				return
+			}
+			c.Set("viewer_location", loc)
+		} else if loc, err := utils.LoadTimezone(c.GetString("user_timezone")); err == nil {
+			c.Set("viewer_location", loc)
+		}
+
+		c.Next()
+	}
+}
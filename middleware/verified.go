@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"net/http"
+	"tools-backend/config"
+	"tools-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireVerifiedEmail restricts unverified accounts to read-only requests when
+// EMAIL_VERIFICATION_POLICY is "read_only" (similar to Laravel's verified middleware)
+func RequireVerifiedEmail() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if config.GetEmailVerificationPolicy() != "read_only" || c.GetBool("email_verified") {
+			c.Next()
+			return
+		}
+
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+
+		utils.ErrorResponse(c, http.StatusForbidden, "Please verify your email address; your account is read-only until then")
+		c.Abort()
+	}
+}
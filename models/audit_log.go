@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Audit actions
+const (
+	AuditAccountLocked   = "account.locked"
+	AuditAccountUnlocked = "account.unlocked"
+	AuditIPLocked        = "ip.locked"
+	AuditRoleChanged     = "user.role_changed"
+	AuditUserSuspended   = "user.suspended"
+	AuditUserReactivated = "user.reactivated"
+	AuditPasswordForced  = "user.password_reset_forced"
+	AuditUsersMerged     = "user.merged"
+
+	AuditImpersonationStarted = "impersonation.started"
+	AuditImpersonatedRequest  = "impersonation.request"
+)
+
+// AuditLog represents a security relevant event
+type AuditLog struct {
+	ID           primitive.ObjectID     `json:"id" bson:"_id,omitempty"`
+	Action       string                 `json:"action" bson:"action"`
+	ActorID      *primitive.ObjectID    `json:"actor_id,omitempty" bson:"actor_id,omitempty"`             // Who did it (nil for the system)
+	TargetUserID *primitive.ObjectID    `json:"target_user_id,omitempty" bson:"target_user_id,omitempty"` // Whose account it affected
+	IP           string                 `json:"ip,omitempty" bson:"ip,omitempty"`
+	UserAgent    string                 `json:"user_agent,omitempty" bson:"user_agent,omitempty"`
+	Details      map[string]interface{} `json:"details,omitempty" bson:"details,omitempty"`
+	CreatedAt    time.Time              `json:"created_at" bson:"created_at"`
+}
@@ -14,6 +14,16 @@ const (
 	RoleAttendee  EventRole = "attendee"
 )
 
+// EventVisibility decides who can see an event besides its participants
+type EventVisibility string
+
+const (
+	VisibilityPrivate  EventVisibility = "private"  // Participants only (the default)
+	VisibilityUnlisted EventVisibility = "unlisted" // Anyone with the ID or link, never listed
+	VisibilityInternal EventVisibility = "internal" // Every user of the platform, listed in search and joinable
+	VisibilityPublic   EventVisibility = "public"   // Everyone, even without an account; in the discovery feed and joinable
+)
+
 // EventStatusValue represents the attendance status for an event
 type EventStatusValue string
 
@@ -22,15 +32,17 @@ const (
 	StatusMaybe      EventStatusValue = "maybe"
 	StatusNotGoing   EventStatusValue = "not_going"
 	StatusNoResponse EventStatusValue = "no_response"
+	StatusWaitlisted EventStatusValue = "waitlisted" // Wanted to go but the event was full; set by the server only
 )
 
 // EventAttendeeDetail represents detailed attendee information
 type EventAttendeeDetail struct {
-	UserID    primitive.ObjectID `json:"user_id"`
-	Name      string             `json:"name"`
-	Email     string             `json:"email"`
-	Status    EventStatusValue   `json:"status"`
-	UpdatedAt time.Time          `json:"updated_at,omitempty"`
+	UserID       primitive.ObjectID `json:"user_id"`
+	Name         string             `json:"name"`
+	Email        string             `json:"email"`
+	Status       EventStatusValue   `json:"status"`
+	WaitlistedAt *time.Time         `json:"waitlisted_at,omitempty"`
+	UpdatedAt    time.Time          `json:"updated_at,omitempty"`
 }
 
 // EventParticipant represents a user's participation in an event
@@ -41,12 +53,41 @@ type EventParticipant struct {
 
 // EventStatus represents an attendee's response to an event invitation
 type EventStatus struct {
-	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
-	EventID   primitive.ObjectID `json:"event_id" bson:"event_id"`
-	UserID    primitive.ObjectID `json:"user_id" bson:"user_id"`
-	Status    EventStatusValue   `json:"status" bson:"status" validate:"required,oneof=going maybe not_going"`
-	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
-	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+	ID             primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	EventID        primitive.ObjectID `json:"event_id" bson:"event_id"`
+	UserID         primitive.ObjectID `json:"user_id" bson:"user_id"`
+	OccurrenceDate string             `json:"occurrence_date,omitempty" bson:"occurrence_date,omitempty"` // Set for one occurrence of a recurring event, empty for the whole event
+	Status         EventStatusValue   `json:"status" bson:"status" validate:"required,oneof=going maybe not_going waitlisted"`
+	WaitlistedAt   *time.Time         `json:"waitlisted_at,omitempty" bson:"waitlisted_at,omitempty"` // Waitlist order, earliest is promoted first
+	CreatedAt      time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt      time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// EventSeats counts the seats taken ("going" answers) of an event with a capacity,
+// per occurrence for recurring events. Seats are taken and released atomically.
+type EventSeats struct {
+	ID             primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	EventID        primitive.ObjectID `json:"event_id" bson:"event_id"`
+	OccurrenceDate string             `json:"occurrence_date" bson:"occurrence_date"` // Empty for non-recurring events
+	Taken          int                `json:"taken" bson:"taken"`
+}
+
+// EventRecurrence makes an event repeat (RFC 5545 RRULE, EXDATE and RDATE).
+// The local date of StartAt is the first occurrence; every occurrence keeps the local start time and duration.
+type EventRecurrence struct {
+	RRule   string   `json:"rrule" bson:"rrule" validate:"required,max=500"`                                         // e.g. FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10
+	ExDates []string `json:"exdates,omitempty" bson:"exdates,omitempty" validate:"max=500,dive,datetime=2006-01-02"` // Skipped occurrences (YYYY-MM-DD)
+	RDates  []string `json:"rdates,omitempty" bson:"rdates,omitempty" validate:"max=500,dive,datetime=2006-01-02"`   // Extra occurrences (YYYY-MM-DD)
+}
+
+// EventOccurrenceOverride holds the changes made to a single occurrence ("this occurrence" edits)
+type EventOccurrenceOverride struct {
+	OccurrenceDate string     `json:"occurrence_date" bson:"occurrence_date"` // Original local date of the occurrence (RFC 5545 RECURRENCE-ID)
+	Title          string     `json:"title,omitempty" bson:"title,omitempty"`
+	Description    string     `json:"description,omitempty" bson:"description,omitempty"`
+	StartAt        *time.Time `json:"start_at,omitempty" bson:"start_at,omitempty"`
+	EndAt          *time.Time `json:"end_at,omitempty" bson:"end_at,omitempty"`
+	Location       string     `json:"location,omitempty" bson:"location,omitempty"`
 }
 
 // Event represents an event in the system
@@ -54,40 +95,108 @@ type Event struct {
 	ID           primitive.ObjectID `json:"id" bson:"_id,omitempty"`
 	Title        string             `json:"title" bson:"title" validate:"required,min=3,max=200"`
 	Description  string             `json:"description" bson:"description" validate:"required,min=10,max=2000"`
-	Date         string             `json:"date" bson:"date" validate:"required"` // ISO 8601 format: YYYY-MM-DD
-	Time         string             `json:"time" bson:"time" validate:"required"` // HH:MM format
+	StartAt      time.Time          `json:"start_at" bson:"start_at" validate:"required"` // Stored in UTC
+	EndAt        time.Time          `json:"end_at" bson:"end_at" validate:"required"`     // Stored in UTC
+	Timezone     string             `json:"timezone" bson:"timezone" validate:"required"` // IANA name, e.g. Europe/Paris
 	Location     string             `json:"location" bson:"location" validate:"required,min=5,max=500"`
+	Capacity     int                `json:"capacity,omitempty" bson:"capacity,omitempty"` // Maximum "going" answers (per occurrence), 0 for no limit
+	Visibility   EventVisibility    `json:"visibility" bson:"visibility,omitempty"`       // Empty for events created before visibility existed (private)
 	Participants []EventParticipant `json:"participants" bson:"participants"`
+	OwnerID      primitive.ObjectID `json:"owner_id" bson:"owner_id,omitempty"` // Organizer allowed to delete the event and hand it over
+	Version      int                `json:"version" bson:"version,omitempty"`   // Increased by every change (the ETag), 0 for events from before versions
 	CreatedAt    time.Time          `json:"created_at" bson:"created_at"`
 	UpdatedAt    time.Time          `json:"updated_at" bson:"updated_at"`
+
+	// Cancelled events stay visible with the reason; deleted events are hidden until restored or purged
+	CancelledAt        *time.Time `json:"cancelled_at,omitempty" bson:"cancelled_at,omitempty"`
+	CancellationReason string     `json:"cancellation_reason,omitempty" bson:"cancellation_reason,omitempty"`
+	DeletedAt          *time.Time `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+
+	// Recurring events
+	Recurrence *EventRecurrence          `json:"recurrence,omitempty" bson:"recurrence,omitempty"`
+	Overrides  []EventOccurrenceOverride `json:"overrides,omitempty" bson:"overrides,omitempty"`
+	SeriesID   *primitive.ObjectID       `json:"series_id,omitempty" bson:"series_id,omitempty"` // Original series when split by a "this and following" edit
 }
 
 // CreateEventRequest represents the data for creating an event
 type CreateEventRequest struct {
-	Title       string `json:"title" validate:"required,min=3,max=200"`
-	Description string `json:"description" validate:"required,min=10,max=2000"`
-	Date        string `json:"date" validate:"required"` // ISO 8601 format: YYYY-MM-DD
-	Time        string `json:"time" validate:"required"` // HH:MM format
-	Location    string `json:"location" validate:"required,min=5,max=500"`
+	Title       string          `json:"title" validate:"required,min=3,max=200"`
+	Description string          `json:"description" validate:"required,min=10,max=2000"`
+	StartAt     time.Time       `json:"start_at" validate:"required"`        // RFC 3339, e.g. 2026-03-14T18:30:00+01:00
+	EndAt       time.Time       `json:"end_at" validate:"required"`          // RFC 3339
+	Timezone    string          `json:"timezone" validate:"required,max=64"` // IANA name, e.g. Europe/Paris
+	Location    string          `json:"location" validate:"required,min=5,max=500"`
+	Capacity    int             `json:"capacity" validate:"omitempty,min=1,max=100000"`                         // Optional seat limit
+	Visibility  EventVisibility `json:"visibility" validate:"omitempty,oneof=private unlisted internal public"` // Default private
+
+	Recurrence *EventRecurrence `json:"recurrence"` // Optional, makes the event repeat
 }
 
-// InviteToEventRequest represents a request to invite users to an event
+// Scopes of an update or delete of a recurring event
+const (
+	ScopeThisOccurrence = "this"
+	ScopeFollowing      = "following"
+	ScopeAllOccurrences = "all"
+)
+
+// InviteToEventRequest represents a request to invite users to an event, by account or by email
+// (addresses without an account get a pending invitation)
 type InviteToEventRequest struct {
-	UserIDs []primitive.ObjectID `json:"user_ids" validate:"required,min=1"`
+	UserIDs []primitive.ObjectID `json:"user_ids" validate:"omitempty,max=100"`
+	Emails  []string             `json:"emails" validate:"omitempty,max=100,dive,email,max=254"`
+}
+
+// CancelEventRequest represents a request to cancel an event (attendees receive the reason)
+type CancelEventRequest struct {
+	Reason string `json:"reason" validate:"required,min=3,max=500"`
+}
+
+// UpdateParticipantRoleRequest represents a request to promote an attendee or demote an organizer
+type UpdateParticipantRoleRequest struct {
+	Role EventRole `json:"role" validate:"required,oneof=organizer attendee"`
+}
+
+// TransferOwnershipRequest represents a request to hand an event over to another participant
+type TransferOwnershipRequest struct {
+	UserID primitive.ObjectID `json:"user_id" validate:"required"`
 }
 
 // UpdateEventRequest represents the data for updating an event
 type UpdateEventRequest struct {
-	Title       string `json:"title" validate:"min=3,max=200"`
-	Description string `json:"description" validate:"min=10,max=2000"`
-	Date        string `json:"date"`
-	Time        string `json:"time"`
-	Location    string `json:"location" validate:"min=5,max=500"`
+	Title       string          `json:"title" validate:"min=3,max=200"`
+	Description string          `json:"description" validate:"min=10,max=2000"`
+	StartAt     *time.Time      `json:"start_at"` // Moving the start alone keeps the duration
+	EndAt       *time.Time      `json:"end_at"`
+	Timezone    string          `json:"timezone" validate:"max=64"`
+	Location    string          `json:"location" validate:"min=5,max=500"`
+	Capacity    *int            `json:"capacity" validate:"omitempty,min=0,max=100000"` // 0 removes the limit
+	Visibility  EventVisibility `json:"visibility" validate:"omitempty,oneof=private unlisted internal public"`
+
+	// Recurring events: which occurrences to change (default "all")
+	Scope          string           `json:"scope" validate:"omitempty,oneof=this following all"`
+	OccurrenceDate string           `json:"occurrence_date"` // Required for "this" and "following"
+	Recurrence     *EventRecurrence `json:"recurrence"`      // Replaces the recurrence (not with "this")
+}
+
+// EventPatch is the editable part of an event as seen by PATCH /events/:id. A patch is applied
+// to it, then only the fields the patch changed are validated. Fields that can be removed with
+// null are optional: description, capacity (no limit), visibility (back to private) and recurrence.
+type EventPatch struct {
+	Title       string           `json:"title" validate:"required,min=3,max=200"`
+	Description string           `json:"description" validate:"omitempty,min=10,max=2000"`
+	StartAt     time.Time        `json:"start_at" validate:"required"` // RFC 3339
+	EndAt       time.Time        `json:"end_at" validate:"required"`
+	Timezone    string           `json:"timezone" validate:"required,max=64"`
+	Location    string           `json:"location" validate:"required,min=5,max=500"`
+	Capacity    int              `json:"capacity,omitempty" validate:"omitempty,min=1,max=100000"`
+	Visibility  EventVisibility  `json:"visibility,omitempty" validate:"omitempty,oneof=private unlisted internal public"`
+	Recurrence  *EventRecurrence `json:"recurrence,omitempty"`
 }
 
 // EventStatusRequest represents a request to update event status
 type EventStatusRequest struct {
-	Status EventStatusValue `json:"status" validate:"required,oneof=going maybe not_going"`
+	Status         EventStatusValue `json:"status" validate:"required,oneof=going maybe not_going"`
+	OccurrenceDate string           `json:"occurrence_date"` // Optional: answer for one occurrence of a recurring event
 }
 
 // EventResponse represents an event sent in API responses
@@ -95,48 +204,185 @@ type EventResponse struct {
 	ID           primitive.ObjectID `json:"id"`
 	Title        string             `json:"title"`
 	Description  string             `json:"description"`
-	Date         string             `json:"date"`
-	Time         string             `json:"time"`
+	StartAt      time.Time          `json:"start_at"` // In the viewer's timezone
+	EndAt        time.Time          `json:"end_at"`
+	Timezone     string             `json:"timezone"` // The event's own timezone
 	Location     string             `json:"location"`
+	Capacity     int                `json:"capacity,omitempty"`
+	Visibility   EventVisibility    `json:"visibility"`
 	Participants []EventParticipant `json:"participants"`
+	OwnerID      primitive.ObjectID `json:"owner_id"`
+	Version      int                `json:"version"` // Same as the ETag header
 	MyStatus     EventStatusValue   `json:"my_status,omitempty"`
 	CreatedAt    time.Time          `json:"created_at"`
 	UpdatedAt    time.Time          `json:"updated_at"`
+
+	CancelledAt        *time.Time `json:"cancelled_at,omitempty"`
+	CancellationReason string     `json:"cancellation_reason,omitempty"`
+	DeletedAt          *time.Time `json:"deleted_at,omitempty"`
+
+	Recurrence     *EventRecurrence          `json:"recurrence,omitempty"`
+	Overrides      []EventOccurrenceOverride `json:"overrides,omitempty"`
+	SeriesID       *primitive.ObjectID       `json:"series_id,omitempty"`
+	OccurrenceDate string                    `json:"occurrence_date,omitempty"` // Set when the response is one occurrence of a recurring event
+}
+
+// PublicEventResponse represents an event shown to people who do not take part in it
+// (no participant list)
+type PublicEventResponse struct {
+	ID                 primitive.ObjectID        `json:"id"`
+	Title              string                    `json:"title"`
+	Description        string                    `json:"description"`
+	StartAt            time.Time                 `json:"start_at"`
+	EndAt              time.Time                 `json:"end_at"`
+	Timezone           string                    `json:"timezone"`
+	Location           string                    `json:"location"`
+	Capacity           int                       `json:"capacity,omitempty"`
+	Visibility         EventVisibility           `json:"visibility"`
+	ParticipantCount   int                       `json:"participant_count"`
+	CancelledAt        *time.Time                `json:"cancelled_at,omitempty"`
+	CancellationReason string                    `json:"cancellation_reason,omitempty"`
+	Recurrence         *EventRecurrence          `json:"recurrence,omitempty"`
+	Overrides          []EventOccurrenceOverride `json:"overrides,omitempty"`
+	OccurrenceDate     string                    `json:"occurrence_date,omitempty"`
 }
 
 // EventStatusResponse represents an event status sent in API responses
 type EventStatusResponse struct {
-	ID        primitive.ObjectID `json:"id"`
-	EventID   primitive.ObjectID `json:"event_id"`
-	UserID    primitive.ObjectID `json:"user_id"`
-	Status    EventStatusValue   `json:"status"`
-	CreatedAt time.Time          `json:"created_at"`
-	UpdatedAt time.Time          `json:"updated_at"`
+	ID             primitive.ObjectID `json:"id"`
+	EventID        primitive.ObjectID `json:"event_id"`
+	UserID         primitive.ObjectID `json:"user_id"`
+	OccurrenceDate string             `json:"occurrence_date,omitempty"`
+	Status         EventStatusValue   `json:"status"`
+	WaitlistedAt   *time.Time         `json:"waitlisted_at,omitempty"`
+	CreatedAt      time.Time          `json:"created_at"`
+	UpdatedAt      time.Time          `json:"updated_at"`
 }
 
 // ToResponse converts Event to EventResponse
 func (e *Event) ToResponse() EventResponse {
 	return EventResponse{
-		ID:           e.ID,
-		Title:        e.Title,
-		Description:  e.Description,
-		Date:         e.Date,
-		Time:         e.Time,
-		Location:     e.Location,
-		Participants: e.Participants,
-		CreatedAt:    e.CreatedAt,
-		UpdatedAt:    e.UpdatedAt,
+		ID:                 e.ID,
+		Title:              e.Title,
+		Description:        e.Description,
+		StartAt:            e.StartAt,
+		EndAt:              e.EndAt,
+		Timezone:           e.Timezone,
+		Location:           e.Location,
+		Capacity:           e.Capacity,
+		Visibility:         e.VisibilityLevel(),
+		Participants:       e.Participants,
+		OwnerID:            e.Owner(),
+		Version:            e.Version,
+		CreatedAt:          e.CreatedAt,
+		UpdatedAt:          e.UpdatedAt,
+		CancelledAt:        e.CancelledAt,
+		CancellationReason: e.CancellationReason,
+		DeletedAt:          e.DeletedAt,
+		Recurrence:         e.Recurrence,
+		Overrides:          e.Overrides,
+		SeriesID:           e.SeriesID,
+	}
+}
+
+// VisibilityLevel returns the visibility of the event (private when it was never set)
+func (e *Event) VisibilityLevel() EventVisibility {
+	if e.Visibility == "" {
+		return VisibilityPrivate
+	}
+	return e.Visibility
+}
+
+// Owner returns the owner of the event; events created before owners existed belong to their first organizer
+func (e *Event) Owner() primitive.ObjectID {
+	if !e.OwnerID.IsZero() {
+		return e.OwnerID
+	}
+	for _, p := range e.Participants {
+		if p.Role == RoleOrganizer {
+			return p.UserID
+		}
+	}
+	return primitive.NilObjectID
+}
+
+// Zone returns the event's timezone (UTC when it cannot be loaded)
+func (e *Event) Zone() *time.Location {
+	if loc, err := time.LoadLocation(e.Timezone); err == nil {
+		return loc
+	}
+	return time.UTC
+}
+
+// LocalDate returns the date the event starts on in its own timezone (YYYY-MM-DD)
+func (e *Event) LocalDate() string {
+	return e.StartAt.In(e.Zone()).Format("2006-01-02")
+}
+
+// Duration returns how long the event lasts
+func (e *Event) Duration() time.Duration {
+	return e.EndAt.Sub(e.StartAt)
+}
+
+// Public strips the participants from an event response
+func (r EventResponse) Public() PublicEventResponse {
+	return PublicEventResponse{
+		ID:                 r.ID,
+		Title:              r.Title,
+		Description:        r.Description,
+		StartAt:            r.StartAt,
+		EndAt:              r.EndAt,
+		Timezone:           r.Timezone,
+		Location:           r.Location,
+		Capacity:           r.Capacity,
+		Visibility:         r.Visibility,
+		ParticipantCount:   len(r.Participants),
+		CancelledAt:        r.CancelledAt,
+		CancellationReason: r.CancellationReason,
+		Recurrence:         r.Recurrence,
+		Overrides:          r.Overrides,
+		OccurrenceDate:     r.OccurrenceDate,
+	}
+}
+
+// InZone renders the start and end times in the given timezone (nil for the event's own timezone)
+func (r EventResponse) InZone(loc *time.Location) EventResponse {
+	if loc == nil {
+		var err error
+		if loc, err = time.LoadLocation(r.Timezone); err != nil {
+			loc = time.UTC
+		}
+	}
+	r.StartAt = r.StartAt.In(loc)
+	r.EndAt = r.EndAt.In(loc)
+	if r.Overrides != nil {
+		overrides := make([]EventOccurrenceOverride, len(r.Overrides))
+		for i, override := range r.Overrides {
+			if override.StartAt != nil {
+				startAt := override.StartAt.In(loc)
+				override.StartAt = &startAt
+			}
+			if override.EndAt != nil {
+				endAt := override.EndAt.In(loc)
+				override.EndAt = &endAt
+			}
+			overrides[i] = override
+		}
+		r.Overrides = overrides
 	}
+	return r
 }
 
 // ToResponse converts EventStatus to EventStatusResponse
 func (es *EventStatus) ToResponse() EventStatusResponse {
 	return EventStatusResponse{
-		ID:        es.ID,
-		EventID:   es.EventID,
-		UserID:    es.UserID,
-		Status:    es.Status,
-		CreatedAt: es.CreatedAt,
-		UpdatedAt: es.UpdatedAt,
+		ID:             es.ID,
+		EventID:        es.EventID,
+		UserID:         es.UserID,
+		OccurrenceDate: es.OccurrenceDate,
+		Status:         es.Status,
+		WaitlistedAt:   es.WaitlistedAt,
+		CreatedAt:      es.CreatedAt,
+		UpdatedAt:      es.UpdatedAt,
 	}
 }
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Event history actions
+const (
+	HistoryCreated              = "created"
+	HistoryUpdated              = "updated"
+	HistoryInvited              = "invited"
+	HistoryJoined               = "joined"
+	HistoryLeft                 = "left"
+	HistoryParticipantRemoved   = "participant_removed"
+	HistoryRoleChanged          = "role_changed"
+	HistoryOwnershipTransferred = "ownership_transferred"
+	HistoryStatusChanged        = "status_changed"
+	HistoryCancelled            = "cancelled"
+	HistoryDeleted              = "deleted"
+	HistoryRestored             = "restored"
+	HistoryReverted             = "reverted"
+)
+
+// FieldChange is the old and new value of one event field (as they appear in the API)
+type FieldChange struct {
+	Field string      `json:"field" bson:"field"`
+	Old   interface{} `json:"old" bson:"old"`
+	New   interface{} `json:"new" bson:"new"`
+}
+
+// EventHistoryEntry is one change of an event: what changed, who changed it and when.
+// Changes of the event itself are recorded under the event version they produced, and the
+// snapshot of the event after the change is what a revert goes back to. Answers are stored
+// apart from the event, so their entries have no version.
+type EventHistoryEntry struct {
+	ID        primitive.ObjectID     `json:"id" bson:"_id,omitempty"`
+	EventID   primitive.ObjectID     `json:"event_id" bson:"event_id"`
+	Version   int                    `json:"version,omitempty" bson:"version,omitempty"` // The event version (ETag) after the change
+	Action    string                 `json:"action" bson:"action"`
+	ActorID   *primitive.ObjectID    `json:"actor_id,omitempty" bson:"actor_id,omitempty"` // nil for guests without an account
+	Changes   []FieldChange          `json:"changes" bson:"changes"`
+	Details   map[string]interface{} `json:"details,omitempty" bson:"details,omitempty"`
+	Snapshot  *Event                 `json:"-" bson:"snapshot,omitempty"`
+	CreatedAt time.Time              `json:"created_at" bson:"created_at"`
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// EventInvitation is a pending invitation for an email address without an account. The guest
+// answers through a signed link; the invitation becomes a participant when the address registers.
+type EventInvitation struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	EventID     primitive.ObjectID `json:"event_id" bson:"event_id"`
+	Email       string             `json:"email" bson:"email"` // Lowercase
+	InvitedBy   primitive.ObjectID `json:"invited_by" bson:"invited_by"`
+	Status      EventStatusValue   `json:"status,omitempty" bson:"status,omitempty"` // The guest's answer, for the whole event
+	RespondedAt *time.Time         `json:"responded_at,omitempty" bson:"responded_at,omitempty"`
+	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// GuestRSVPRequest represents a guest's answer sent through an invitation link
+type GuestRSVPRequest struct {
+	Status EventStatusValue `json:"status" validate:"required,oneof=going maybe not_going"`
+}
+
+// EventInvitationResponse represents a pending invitation sent in API responses
+type EventInvitationResponse struct {
+	ID          primitive.ObjectID `json:"id"`
+	EventID     primitive.ObjectID `json:"event_id"`
+	Email       string             `json:"email"`
+	Status      EventStatusValue   `json:"status"`
+	RespondedAt *time.Time         `json:"responded_at,omitempty"`
+	CreatedAt   time.Time          `json:"created_at"`
+}
+
+// ToResponse converts EventInvitation to EventInvitationResponse
+func (i *EventInvitation) ToResponse() EventInvitationResponse {
+	status := i.Status
+	if status == "" {
+		status = StatusNoResponse
+	}
+	return EventInvitationResponse{
+		ID:          i.ID,
+		EventID:     i.EventID,
+		Email:       i.Email,
+		Status:      status,
+		RespondedAt: i.RespondedAt,
+		CreatedAt:   i.CreatedAt,
+	}
+}
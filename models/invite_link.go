@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// EventInviteLink is a shareable link that makes whoever redeems it an attendee of the event
+// (only the hash of the token is stored)
+type EventInviteLink struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	EventID   primitive.ObjectID `json:"event_id" bson:"event_id"`
+	CreatedBy primitive.ObjectID `json:"created_by" bson:"created_by"`
+	Prefix    string             `json:"prefix" bson:"prefix"` // First characters of the token, to recognize it
+	TokenHash string             `json:"-" bson:"token_hash"`
+	ExpiresAt *time.Time         `json:"expires_at,omitempty" bson:"expires_at,omitempty"`
+	MaxUses   int                `json:"max_uses" bson:"max_uses"` // 0 means unlimited
+	Uses      int                `json:"uses" bson:"uses"`
+	Domain    string             `json:"domain,omitempty" bson:"domain,omitempty"` // Only addresses of this domain may redeem it
+	RevokedAt *time.Time         `json:"revoked_at,omitempty" bson:"revoked_at,omitempty"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// CreateInviteLinkRequest represents the data for creating an invite link (every limit is optional)
+type CreateInviteLinkRequest struct {
+	ExpiresInHours int    `json:"expires_in_hours" validate:"omitempty,min=1,max=8760"`
+	MaxUses        int    `json:"max_uses" validate:"omitempty,min=1,max=100000"`
+	Domain         string `json:"domain" validate:"omitempty,fqdn,max=253"` // e.g. example.com
+}
+
+// EventInviteLinkResponse represents an invite link sent in API responses
+type EventInviteLinkResponse struct {
+	ID        primitive.ObjectID `json:"id"`
+	EventID   primitive.ObjectID `json:"event_id"`
+	CreatedBy primitive.ObjectID `json:"created_by"`
+	Prefix    string             `json:"prefix"`
+	ExpiresAt *time.Time         `json:"expires_at"`
+	MaxUses   int                `json:"max_uses"`
+	Uses      int                `json:"uses"`
+	Domain    string             `json:"domain,omitempty"`
+	Active    bool               `json:"active"`
+	RevokedAt *time.Time         `json:"revoked_at,omitempty"`
+	CreatedAt time.Time          `json:"created_at"`
+}
+
+// IsActive reports whether the link can still be redeemed
+func (l *EventInviteLink) IsActive() bool {
+	if l.RevokedAt != nil {
+		return false
+	}
+	if l.ExpiresAt != nil && !time.Now().Before(*l.ExpiresAt) {
+		return false
+	}
+	return l.MaxUses == 0 || l.Uses < l.MaxUses
+}
+
+// ToResponse converts EventInviteLink to EventInviteLinkResponse
+func (l *EventInviteLink) ToResponse() EventInviteLinkResponse {
+	return EventInviteLinkResponse{
+		ID:        l.ID,
+		EventID:   l.EventID,
+		CreatedBy: l.CreatedBy,
+		Prefix:    l.Prefix,
+		ExpiresAt: l.ExpiresAt,
+		MaxUses:   l.MaxUses,
+		Uses:      l.Uses,
+		Domain:    l.Domain,
+		Active:    l.IsActive(),
+		RevokedAt: l.RevokedAt,
+		CreatedAt: l.CreatedAt,
+	}
+}
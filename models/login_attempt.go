@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// LoginAttempt tracks failed logins for one account or one IP address
+type LoginAttempt struct {
+	ID            primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Key           string             `json:"key" bson:"key"` // "account:<email>" or "ip:<address>"
+	Failures      int                `json:"failures" bson:"failures"`
+	LastFailureAt time.Time          `json:"last_failure_at" bson:"last_failure_at"`
+	LockedUntil   *time.Time         `json:"locked_until,omitempty" bson:"locked_until,omitempty"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// OIDCState stores a pending OpenID Connect login between the redirect and the callback
+type OIDCState struct {
+	ID           primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	State        string             `json:"-" bson:"state"`
+	Provider     string             `json:"provider" bson:"provider"`
+	Nonce        string             `json:"-" bson:"nonce"`
+	CodeVerifier string             `json:"-" bson:"code_verifier"` // PKCE verifier, never leaves the server
+	ExpiresAt    time.Time          `json:"expires_at" bson:"expires_at"`
+	CreatedAt    time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// OIDCCallbackRequest represents the authorization response forwarded by the frontend
+type OIDCCallbackRequest struct {
+	Code  string `json:"code" form:"code"`
+	State string `json:"state" form:"state"`
+	Error string `json:"error" form:"error"`
+}
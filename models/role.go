@@ -0,0 +1,54 @@
+package models
+
+// PlatformRole is a user's role across the whole platform (not to be confused with EventRole)
+type PlatformRole string
+
+const (
+	PlatformRoleUser      PlatformRole = "user"
+	PlatformRoleModerator PlatformRole = "moderator"
+	PlatformRoleAdmin     PlatformRole = "admin"
+)
+
+// Permission is something a platform role allows beyond a user's own events
+type Permission string
+
+const (
+	PermissionViewAnyEvent   Permission = "events.view_any"   // Read any event and its attendees
+	PermissionManageAnyEvent Permission = "events.manage_any" // Update, invite to and delete any event
+	PermissionManageUsers    Permission = "users.manage"      // List users and change their roles
+)
+
+// rolePermissions maps each role to its permissions (similar to Laravel's gates)
+var rolePermissions = map[PlatformRole][]Permission{
+	PlatformRoleUser:      {},
+	PlatformRoleModerator: {PermissionViewAnyEvent, PermissionManageAnyEvent},
+	PlatformRoleAdmin:     {PermissionViewAnyEvent, PermissionManageAnyEvent, PermissionManageUsers},
+}
+
+// roleRanks orders the roles from least to most privileged
+var roleRanks = map[PlatformRole]int{
+	PlatformRoleUser:      0,
+	PlatformRoleModerator: 1,
+	PlatformRoleAdmin:     2,
+}
+
+// Outranks reports whether the role is more privileged than the other one
+func (r PlatformRole) Outranks(other PlatformRole) bool {
+	return roleRanks[r] > roleRanks[other]
+}
+
+// IsValid reports whether the role is one of the known platform roles
+func (r PlatformRole) IsValid() bool {
+	_, ok := rolePermissions[r]
+	return ok
+}
+
+// Can reports whether the role grants the permission
+func (r PlatformRole) Can(permission Permission) bool {
+	for _, p := range rolePermissions[r] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RefreshToken represents a rotating refresh token (only the hash is stored)
+type RefreshToken struct {
+	ID              primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	UserID          primitive.ObjectID  `json:"user_id" bson:"user_id"`
+	TokenHash       string              `json:"-" bson:"token_hash"`
+	FamilyID        primitive.ObjectID  `json:"family_id" bson:"family_id"` // All tokens rotated from the same login
+	AccessJTI       string              `json:"-" bson:"access_jti"`        // jti of the access token issued alongside
+	AccessExpiresAt time.Time           `json:"-" bson:"access_expires_at"` // Expiry of that access token
+	ExpiresAt       time.Time           `json:"expires_at" bson:"expires_at"`
+	RevokedAt       *time.Time          `json:"revoked_at,omitempty" bson:"revoked_at,omitempty"`
+	ReplacedBy      *primitive.ObjectID `json:"replaced_by,omitempty" bson:"replaced_by,omitempty"`
+	CreatedAt       time.Time           `json:"created_at" bson:"created_at"`
+}
+
+// RevokedToken represents an access token jti on the deny-list
+type RevokedToken struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	JTI       string             `json:"jti" bson:"jti"`
+	UserID    primitive.ObjectID `json:"user_id" bson:"user_id"`
+	ExpiresAt time.Time          `json:"expires_at" bson:"expires_at"` // Entry is useless once the token expired
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// RefreshTokenRequest represents a request to exchange a refresh token
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// LogoutRequest represents a logout request (refresh token is optional)
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
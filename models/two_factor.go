@@ -0,0 +1,20 @@
+package models
+
+// TwoFactorCodeRequest represents a request carrying a TOTP code
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required,len=6,numeric"`
+}
+
+// TwoFactorDisableRequest represents a request to turn off two-factor authentication
+type TwoFactorDisableRequest struct {
+	Password     string `json:"password"`      // Not required for accounts without a password (single sign-on)
+	Code         string `json:"code"`          // TOTP code, or
+	RecoveryCode string `json:"recovery_code"` // one of the recovery codes
+}
+
+// TwoFactorLoginRequest represents the second step of a login with two-factor authentication
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code"`          // TOTP code, or
+	RecoveryCode   string `json:"recovery_code"` // one of the recovery codes
+}
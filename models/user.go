@@ -8,12 +8,47 @@ import (
 
 // User represents a user in the system (similar to Laravel's User model)
 type User struct {
-	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
-	Name      string             `json:"name" bson:"name" validate:"required,min=2,max=100"`
-	Email     string             `json:"email" bson:"email" validate:"required,email"`
-	Password  string             `json:"-" bson:"password" validate:"required,min=6"`
-	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
-	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+	ID           primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Name         string             `json:"name" bson:"name" validate:"required,min=2,max=100"`
+	Email        string             `json:"email" bson:"email" validate:"required,email"`
+	Password     string             `json:"-" bson:"password" validate:"required,min=6"`
+	VerifiedAt   *time.Time         `json:"verified_at,omitempty" bson:"verified_at,omitempty"`     // nil until the email address is confirmed
+	PendingEmail string             `json:"pending_email,omitempty" bson:"pending_email,omitempty"` // New address waiting for confirmation
+	Role         PlatformRole       `json:"role" bson:"role,omitempty"`                             // Empty means PlatformRoleUser
+	Timezone     string             `json:"timezone,omitempty" bson:"timezone,omitempty"`           // IANA name event times are shown in
+	CreatedAt    time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at" bson:"updated_at"`
+
+	// Account lifecycle (managed by admins)
+	SuspendedAt           *time.Time `json:"suspended_at,omitempty" bson:"suspended_at,omitempty"` // Suspended accounts cannot log in or use existing tokens
+	SuspendedReason       string     `json:"suspended_reason,omitempty" bson:"suspended_reason,omitempty"`
+	PasswordResetRequired bool       `json:"password_reset_required,omitempty" bson:"password_reset_required,omitempty"` // Password login is refused until the password is reset
+
+	// Two-factor authentication (TOTP)
+	TwoFactorEnabled       bool     `json:"two_factor_enabled" bson:"two_factor_enabled"`
+	TwoFactorSecret        string   `json:"-" bson:"two_factor_secret,omitempty"`
+	TwoFactorPendingSecret string   `json:"-" bson:"two_factor_pending_secret,omitempty"` // Set by setup, moved to TwoFactorSecret on confirm
+	TwoFactorLastStep      int64    `json:"-" bson:"two_factor_last_step,omitempty"`      // Last accepted time step, prevents code replay
+	RecoveryCodes          []string `json:"-" bson:"recovery_codes,omitempty"`            // SHA-256 hashes of unused recovery codes
+
+	// Linked external identities (OpenID Connect)
+	Identities []UserIdentity `json:"-" bson:"identities,omitempty"`
+}
+
+// EffectiveRole returns the user's role, defaulting to PlatformRoleUser for accounts without one
+func (u *User) EffectiveRole() PlatformRole {
+	if u.Role == "" {
+		return PlatformRoleUser
+	}
+	return u.Role
+}
+
+// UserIdentity links a user to an account at an OpenID Connect provider
+type UserIdentity struct {
+	Provider string    `json:"provider" bson:"provider"`
+	Subject  string    `json:"subject" bson:"subject"` // The provider's stable "sub" claim
+	Email    string    `json:"email" bson:"email"`
+	LinkedAt time.Time `json:"linked_at" bson:"linked_at"`
 }
 
 // UserRegistrationRequest represents the data for user registration
@@ -23,22 +58,86 @@ type UserRegistrationRequest struct {
 	Password string `json:"password" validate:"required,min=6"`
 }
 
+// ResendVerificationRequest represents a request to resend the verification email
+type ResendVerificationRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// UpdateProfileRequest represents the profile fields a user can change
+type UpdateProfileRequest struct {
+	Name     string `json:"name" validate:"required,min=2,max=100"`
+	Timezone string `json:"timezone" validate:"max=64"` // Optional IANA name, e.g. Europe/Paris
+}
+
+// ChangePasswordRequest represents a request to change the password
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"` // Not required for accounts without a password (single sign-on)
+	NewPassword     string `json:"new_password" validate:"required,min=6"`
+}
+
+// ChangeEmailRequest represents a request to change the email address
+type ChangeEmailRequest struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password"`
+}
+
+// DeleteAccountRequest represents a request to delete the account
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+}
+
+// UpdateUserRoleRequest represents an admin request to change a user's platform role
+type UpdateUserRoleRequest struct {
+	Role PlatformRole `json:"role" validate:"required,oneof=user moderator admin"`
+}
+
+// SuspendUserRequest represents an admin request to suspend an account
+type SuspendUserRequest struct {
+	Reason string `json:"reason" validate:"max=500"`
+}
+
+// ImpersonateUserRequest represents an admin request to act as another user
+type ImpersonateUserRequest struct {
+	Reason string `json:"reason" validate:"required,min=3,max=500"` // Why support needs to see the user's view, kept in the audit log
+}
+
+// MergeUsersRequest represents an admin request to merge a duplicate account into another one
+type MergeUsersRequest struct {
+	SourceUserID primitive.ObjectID `json:"source_user_id" validate:"required"`
+}
+
 // UserResponse represents the user data sent in API responses (without password)
 type UserResponse struct {
-	ID        primitive.ObjectID `json:"id"`
-	Name      string             `json:"name"`
-	Email     string             `json:"email"`
-	CreatedAt time.Time          `json:"created_at"`
-	UpdatedAt time.Time          `json:"updated_at"`
+	ID               primitive.ObjectID `json:"id"`
+	Name             string             `json:"name"`
+	Email            string             `json:"email"`
+	VerifiedAt       *time.Time         `json:"verified_at"`
+	PendingEmail     string             `json:"pending_email,omitempty"`
+	Role             PlatformRole       `json:"role"`
+	Timezone         string             `json:"timezone,omitempty"`
+	TwoFactorEnabled bool               `json:"two_factor_enabled"`
+	SuspendedAt      *time.Time         `json:"suspended_at,omitempty"`
+	SuspendedReason  string             `json:"suspended_reason,omitempty"`
+	ResetRequired    bool               `json:"password_reset_required,omitempty"`
+	CreatedAt        time.Time          `json:"created_at"`
+	UpdatedAt        time.Time          `json:"updated_at"`
 }
 
 // ToResponse converts User to UserResponse (similar to Laravel's API resources)
 func (u *User) ToResponse() UserResponse {
 	return UserResponse{
-		ID:        u.ID,
-		Name:      u.Name,
-		Email:     u.Email,
-		CreatedAt: u.CreatedAt,
-		UpdatedAt: u.UpdatedAt,
+		ID:               u.ID,
+		Name:             u.Name,
+		Email:            u.Email,
+		VerifiedAt:       u.VerifiedAt,
+		PendingEmail:     u.PendingEmail,
+		Role:             u.EffectiveRole(),
+		Timezone:         u.Timezone,
+		TwoFactorEnabled: u.TwoFactorEnabled,
+		SuspendedAt:      u.SuspendedAt,
+		SuspendedReason:  u.SuspendedReason,
+		ResetRequired:    u.PasswordResetRequired,
+		CreatedAt:        u.CreatedAt,
+		UpdatedAt:        u.UpdatedAt,
 	}
 }
@@ -0,0 +1,179 @@
+package oidc
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// jwk is a single JSON Web Key from the provider key set
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// keyCache caches the provider public keys and refetches them when an unknown kid shows up
+type keyCache struct {
+	uri string
+
+	mu        sync.Mutex
+	keys      map[string]interface{}
+	fetchedAt time.Time
+}
+
+// minRefreshInterval limits how often an unknown kid can trigger a refetch
+const minRefreshInterval = time.Minute
+
+// get returns the public key for kid, refreshing the key set if needed
+func (kc *keyCache) get(kid, alg string) (interface{}, error) {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+
+	if key, ok := kc.lookup(kid, alg); ok {
+		return key, nil
+	}
+
+	if time.Since(kc.fetchedAt) < minRefreshInterval && kc.keys != nil {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+
+	if err := kc.fetch(); err != nil {
+		return nil, err
+	}
+
+	if key, ok := kc.lookup(kid, alg); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown key id %q", kid)
+}
+
+// lookup finds a key by kid (or the only matching key when the token has no kid)
+func (kc *keyCache) lookup(kid, alg string) (interface{}, bool) {
+	if kid != "" {
+		key, ok := kc.keys[kid+"|"+alg]
+		return key, ok
+	}
+
+	var match interface{}
+	count := 0
+	for id, key := range kc.keys {
+		if strings.HasSuffix(id, "|"+alg) {
+			match = key
+			count++
+		}
+	}
+	return match, count == 1
+}
+
+// fetch downloads and parses the JWKS document
+func (kc *keyCache) fetch() error {
+	resp, err := httpClient.Get(kc.uri)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("jwks endpoint returned status %d", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return err
+	}
+
+	keys := make(map[string]interface{})
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, algs, err := k.publicKey()
+		if err != nil {
+			continue // Skip keys we do not understand
+		}
+		for _, alg := range algs {
+			if k.Alg == "" || k.Alg == alg {
+				keys[k.Kid+"|"+alg] = key
+			}
+		}
+	}
+
+	kc.keys = keys
+	kc.fetchedAt = time.Now()
+	return nil
+}
+
+// publicKey converts the JWK to a Go public key and the algorithms it may be used with
+func (k jwk) publicKey() (interface{}, []string, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, []string{"RS256", "RS384", "RS512"}, nil
+	case "EC":
+		var curve elliptic.Curve
+		var alg string
+		switch k.Crv {
+		case "P-256":
+			curve, alg = elliptic.P256(), "ES256"
+		case "P-384":
+			curve, alg = elliptic.P384(), "ES384"
+		default:
+			return nil, nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, []string{alg}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, nil, errors.New("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), []string{"EdDSA"}, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+// decodeBigInt decodes a base64url encoded big-endian integer
+func decodeBigInt(value string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,345 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// mockIssuer is a local OpenID Connect provider serving discovery, JWKS and token endpoints
+type mockIssuer struct {
+	t      *testing.T
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	kid    string
+
+	// What the next authorization code exchange expects and returns
+	code          string
+	codeChallenge string
+	claims        jwt.MapClaims
+}
+
+func newMockIssuer(t *testing.T) *mockIssuer {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := &mockIssuer{t: t, key: key, kid: "key-1"}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 m.server.URL,
+			"authorization_endpoint": m.server.URL + "/authorize",
+			"token_endpoint":         m.server.URL + "/token",
+			"jwks_uri":               m.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": m.kid,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(m.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(m.key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "bad form", http.StatusBadRequest)
+			return
+		}
+		clientID, secret, _ := r.BasicAuth()
+		if r.Form.Get("grant_type") != "authorization_code" || r.Form.Get("code") != m.code ||
+			CodeChallengeS256(r.Form.Get("code_verifier")) != m.codeChallenge ||
+			clientID != "tools-backend" || secret != "secret" {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{
+			"access_token": "access",
+			"token_type":   "Bearer",
+			"id_token":     m.sign(m.claims),
+		})
+	})
+
+	m.server = httptest.NewServer(mux)
+	t.Cleanup(m.server.Close)
+	return m
+}
+
+// provider returns a provider configured for the mock issuer
+func (m *mockIssuer) provider() *Provider {
+	return &Provider{
+		Name:         "mock",
+		Issuer:       m.server.URL,
+		ClientID:     "tools-backend",
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost:8080/api/v1/oidc/mock/callback",
+		Scopes:       []string{"openid", "email", "profile"},
+	}
+}
+
+// validClaims returns the claims of an ID token the provider should accept
+func (m *mockIssuer) validClaims(nonce string) jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":            m.server.URL,
+		"aud":            "tools-backend",
+		"sub":            "user-123",
+		"email":          "jane@example.com",
+		"email_verified": true,
+		"name":           "Jane",
+		"nonce":          nonce,
+		"iat":            time.Now().Unix(),
+		"exp":            time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func (m *mockIssuer) sign(claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = m.kid
+	signed, err := token.SignedString(m.key)
+	if err != nil {
+		m.t.Error(err) // Also called by the token handler, outside the test goroutine
+	}
+	return signed
+}
+
+func TestLoginAgainstMockIssuer(t *testing.T) {
+	issuer := newMockIssuer(t)
+	provider := issuer.provider()
+
+	verifier := "verifier-0123456789"
+	authURL, err := provider.AuthCodeURL("state-1", "nonce-1", CodeChallengeS256(verifier))
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := parsed.Query()
+	if !strings.HasPrefix(authURL, issuer.server.URL+"/authorize?") {
+		t.Errorf("authorization URL %q does not use the discovered endpoint", authURL)
+	}
+	for name, want := range map[string]string{
+		"response_type":         "code",
+		"client_id":             "tools-backend",
+		"state":                 "state-1",
+		"nonce":                 "nonce-1",
+		"code_challenge":        CodeChallengeS256(verifier),
+		"code_challenge_method": "S256",
+		"scope":                 "openid email profile",
+	} {
+		if got := query.Get(name); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+
+	// The provider redirects back with a code, which is exchanged with the PKCE verifier
+	issuer.code = "code-1"
+	issuer.codeChallenge = query.Get("code_challenge")
+	issuer.claims = issuer.validClaims("nonce-1")
+
+	tokens, err := provider.Exchange("code-1", verifier)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+
+	claims, err := provider.VerifyIDToken(tokens.IDToken, "nonce-1")
+	if err != nil {
+		t.Fatalf("VerifyIDToken: %v", err)
+	}
+	if claims.Subject != "user-123" || claims.Email != "jane@example.com" || !claims.EmailVerified || claims.Name != "Jane" {
+		t.Errorf("unexpected claims %+v", claims)
+	}
+}
+
+func TestExchangeRejectsWrongCodeOrVerifier(t *testing.T) {
+	issuer := newMockIssuer(t)
+	provider := issuer.provider()
+	issuer.code = "code-1"
+	issuer.codeChallenge = CodeChallengeS256("verifier-0123456789")
+	issuer.claims = issuer.validClaims("nonce-1")
+
+	if _, err := provider.Exchange("other-code", "verifier-0123456789"); err == nil {
+		t.Error("Exchange accepted an unknown code")
+	}
+	if _, err := provider.Exchange("code-1", "another-verifier"); err == nil {
+		t.Error("Exchange accepted a wrong PKCE verifier")
+	}
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	issuer := newMockIssuer(t)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		token   func() string
+		wantErr bool
+		check   func(*IDTokenClaims) bool
+	}{
+		{
+			name:  "valid",
+			token: func() string { return issuer.sign(issuer.validClaims("nonce-1")) },
+		},
+		{
+			name: "email_verified as a string",
+			token: func() string {
+				claims := issuer.validClaims("nonce-1")
+				claims["email_verified"] = "true"
+				return issuer.sign(claims)
+			},
+			check: func(c *IDTokenClaims) bool { return c.EmailVerified },
+		},
+		{
+			name: "email not verified",
+			token: func() string {
+				claims := issuer.validClaims("nonce-1")
+				claims["email_verified"] = false
+				return issuer.sign(claims)
+			},
+			check: func(c *IDTokenClaims) bool { return !c.EmailVerified },
+		},
+		{
+			name:    "wrong nonce",
+			token:   func() string { return issuer.sign(issuer.validClaims("nonce-2")) },
+			wantErr: true,
+		},
+		{
+			name: "wrong audience",
+			token: func() string {
+				claims := issuer.validClaims("nonce-1")
+				claims["aud"] = "someone-else"
+				return issuer.sign(claims)
+			},
+			wantErr: true,
+		},
+		{
+			name: "wrong issuer",
+			token: func() string {
+				claims := issuer.validClaims("nonce-1")
+				claims["iss"] = "https://evil.example.com"
+				return issuer.sign(claims)
+			},
+			wantErr: true,
+		},
+		{
+			name: "expired",
+			token: func() string {
+				claims := issuer.validClaims("nonce-1")
+				claims["exp"] = time.Now().Add(-time.Minute).Unix()
+				return issuer.sign(claims)
+			},
+			wantErr: true,
+		},
+		{
+			name: "several audiences without us as authorized party",
+			token: func() string {
+				claims := issuer.validClaims("nonce-1")
+				claims["aud"] = []string{"tools-backend", "someone-else"}
+				claims["azp"] = "someone-else"
+				return issuer.sign(claims)
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing subject",
+			token: func() string {
+				claims := issuer.validClaims("nonce-1")
+				delete(claims, "sub")
+				return issuer.sign(claims)
+			},
+			wantErr: true,
+		},
+		{
+			name: "signed by another key",
+			token: func() string {
+				token := jwt.NewWithClaims(jwt.SigningMethodRS256, issuer.validClaims("nonce-1"))
+				token.Header["kid"] = issuer.kid
+				signed, _ := token.SignedString(otherKey)
+				return signed
+			},
+			wantErr: true,
+		},
+		{
+			name: "symmetric algorithm",
+			token: func() string {
+				signed, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, issuer.validClaims("nonce-1")).SignedString([]byte("secret"))
+				return signed
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := issuer.provider().VerifyIDToken(tt.token(), "nonce-1")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected the token to be rejected")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("VerifyIDToken: %v", err)
+			}
+			if tt.check != nil && !tt.check(claims) {
+				t.Errorf("unexpected claims %+v", claims)
+			}
+		})
+	}
+}
+
+func TestVerifyIDTokenFetchesRotatedKeys(t *testing.T) {
+	issuer := newMockIssuer(t)
+	provider := issuer.provider()
+
+	if _, err := provider.VerifyIDToken(issuer.sign(issuer.validClaims("nonce-1")), "nonce-1"); err != nil {
+		t.Fatalf("VerifyIDToken: %v", err)
+	}
+
+	// The provider rotates to a new key; once the refresh interval has passed it is fetched
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer.key, issuer.kid = newKey, "key-2"
+	rotated := issuer.sign(issuer.validClaims("nonce-1"))
+
+	if _, err := provider.VerifyIDToken(rotated, "nonce-1"); err == nil {
+		t.Fatal("an unknown kid refetched the key set before the refresh interval")
+	}
+	provider.keys.fetchedAt = time.Now().Add(-minRefreshInterval)
+	if _, err := provider.VerifyIDToken(rotated, "nonce-1"); err != nil {
+		t.Fatalf("VerifyIDToken after rotation: %v", err)
+	}
+}
+
+func TestDiscoverRejectsIssuerMismatch(t *testing.T) {
+	issuer := newMockIssuer(t)
+	provider := issuer.provider()
+	provider.Issuer = issuer.server.URL + "/"
+
+	if _, err := provider.Discover(); err == nil {
+		t.Error("Discover accepted a document for another issuer")
+	}
+}
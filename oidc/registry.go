@@ -0,0 +1,68 @@
+package oidc
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"sort"
+	"strings"
+	"sync"
+	"tools-backend/config"
+)
+
+var (
+	registryOnce sync.Once
+	providers    map[string]*Provider
+)
+
+// loadProviders reads the providers listed in OIDC_PROVIDERS, e.g. OIDC_PROVIDERS=corp with
+// OIDC_CORP_ISSUER, OIDC_CORP_CLIENT_ID, OIDC_CORP_CLIENT_SECRET, OIDC_CORP_REDIRECT_URL and OIDC_CORP_SCOPES
+func loadProviders() {
+	providers = make(map[string]*Provider)
+
+	for _, name := range strings.Split(config.GetEnv("OIDC_PROVIDERS", ""), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		issuer := config.GetEnv(prefix+"ISSUER", "")
+		clientID := config.GetEnv(prefix+"CLIENT_ID", "")
+		if issuer == "" || clientID == "" {
+			continue
+		}
+
+		providers[name] = &Provider{
+			Name:         name,
+			Issuer:       issuer,
+			ClientID:     clientID,
+			ClientSecret: config.GetEnv(prefix+"CLIENT_SECRET", ""),
+			RedirectURL:  config.GetEnv(prefix+"REDIRECT_URL", config.GetAppURL()+"/api/v1/oidc/"+name+"/callback"),
+			Scopes:       strings.Fields(config.GetEnv(prefix+"SCOPES", "openid email profile")),
+		}
+	}
+}
+
+// GetProvider returns a configured provider by name
+func GetProvider(name string) (*Provider, bool) {
+	registryOnce.Do(loadProviders)
+	provider, ok := providers[name]
+	return provider, ok
+}
+
+// ProviderNames returns the names of all configured providers
+func ProviderNames() []string {
+	registryOnce.Do(loadProviders)
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CodeChallengeS256 derives the PKCE code challenge from a verifier (RFC 7636)
+func CodeChallengeS256(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
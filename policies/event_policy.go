@@ -0,0 +1,123 @@
+package policies
+
+import (
+	"tools-backend/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Actor is the authenticated user an authorization decision is made for
+type Actor struct {
+	UserID primitive.ObjectID
+	Role   models.PlatformRole
+}
+
+// Can reports whether the actor's platform role grants the permission
+func (a Actor) Can(permission models.Permission) bool {
+	return a.Role.Can(permission)
+}
+
+// IsOrganizer reports whether the user organizes the event
+func IsOrganizer(event *models.Event, userID primitive.ObjectID) bool {
+	for _, p := range event.Participants {
+		if p.UserID == userID && p.Role == models.RoleOrganizer {
+			return true
+		}
+	}
+	return false
+}
+
+// IsOwner reports whether the user owns the event
+func IsOwner(event *models.Event, userID primitive.ObjectID) bool {
+	return !userID.IsZero() && event.Owner() == userID
+}
+
+// IsParticipant reports whether the user organizes or is invited to the event
+func IsParticipant(event *models.Event, userID primitive.ObjectID) bool {
+	for _, p := range event.Participants {
+		if p.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// CanViewEvent allows participants, staff who may view any event, and every user when the
+// event is not private
+func CanViewEvent(actor Actor, event *models.Event) bool {
+	return event.VisibilityLevel() != models.VisibilityPrivate ||
+		IsParticipant(event, actor.UserID) || actor.Can(models.PermissionViewAnyEvent)
+}
+
+// CanViewAnonymously allows people without an account to see public and unlisted events
+func CanViewAnonymously(event *models.Event) bool {
+	visibility := event.VisibilityLevel()
+	return visibility == models.VisibilityPublic || visibility == models.VisibilityUnlisted
+}
+
+// CanViewHistory allows participants and staff who may view any event to see what changed
+func CanViewHistory(actor Actor, event *models.Event) bool {
+	return IsParticipant(event, actor.UserID) || actor.Can(models.PermissionViewAnyEvent)
+}
+
+// CanJoin allows every user to join public and internal events on their own
+func CanJoin(actor Actor, event *models.Event) bool {
+	visibility := event.VisibilityLevel()
+	return visibility == models.VisibilityPublic || visibility == models.VisibilityInternal
+}
+
+// CanEditEvent allows organizers and staff who may manage any event
+func CanEditEvent(actor Actor, event *models.Event) bool {
+	return IsOrganizer(event, actor.UserID) || actor.Can(models.PermissionManageAnyEvent)
+}
+
+// CanInvite allows organizers and staff who may manage any event
+func CanInvite(actor Actor, event *models.Event) bool {
+	return IsOrganizer(event, actor.UserID) || actor.Can(models.PermissionManageAnyEvent)
+}
+
+// CanDeleteEvent allows the owner and staff who may manage any event; co-organizers may only
+// remove single occurrences (see CanEditEvent)
+func CanDeleteEvent(actor Actor, event *models.Event) bool {
+	return IsOwner(event, actor.UserID) || actor.Can(models.PermissionManageAnyEvent)
+}
+
+// CanCancelEvent allows the owner and staff who may manage any event
+func CanCancelEvent(actor Actor, event *models.Event) bool {
+	return IsOwner(event, actor.UserID) || actor.Can(models.PermissionManageAnyEvent)
+}
+
+// CanPromote allows organizers and staff who may manage any event to make an attendee an organizer
+func CanPromote(actor Actor, event *models.Event) bool {
+	return IsOrganizer(event, actor.UserID) || actor.Can(models.PermissionManageAnyEvent)
+}
+
+// CanDemote allows the owner, the organizer themselves (stepping down) and staff who may
+// manage any event to make an organizer an attendee again
+func CanDemote(actor Actor, event *models.Event, userID primitive.ObjectID) bool {
+	return IsOwner(event, actor.UserID) || actor.UserID == userID || actor.Can(models.PermissionManageAnyEvent)
+}
+
+// CanRemoveParticipant allows organizers and staff who may manage any event to uninvite attendees;
+// organizers can only be removed by those who may demote them
+func CanRemoveParticipant(actor Actor, event *models.Event, participant models.EventParticipant) bool {
+	if participant.Role == models.RoleOrganizer {
+		return CanDemote(actor, event, participant.UserID)
+	}
+	return IsOrganizer(event, actor.UserID) || actor.Can(models.PermissionManageAnyEvent)
+}
+
+// CanTransferOwnership allows the owner and staff who may manage any event (e.g. when the owner left)
+func CanTransferOwnership(actor Actor, event *models.Event) bool {
+	return IsOwner(event, actor.UserID) || actor.Can(models.PermissionManageAnyEvent)
+}
+
+// CanViewAttendees allows organizers and staff who may view any event
+func CanViewAttendees(actor Actor, event *models.Event) bool {
+	return IsOrganizer(event, actor.UserID) || actor.Can(models.PermissionViewAnyEvent)
+}
+
+// CanRespond allows only participants; an RSVP is always personal, so roles grant nothing here
+func CanRespond(actor Actor, event *models.Event) bool {
+	return IsParticipant(event, actor.UserID)
+}
@@ -0,0 +1,290 @@
+package recurrence
+
+import (
+	"sort"
+	"time"
+)
+
+// maxPeriods stops the expansion of rules that never (or hardly ever) match, e.g. February 30th
+const maxPeriods = 10000
+
+// Set is a recurring series: a start date, a rule and extra (RDATE) or excluded (EXDATE) dates.
+// All dates are calendar dates at midnight UTC; the time of day belongs to the event.
+type Set struct {
+	Start   time.Time
+	Rule    *Rule
+	ExDates []time.Time
+	RDates  []time.Time
+}
+
+// Between returns the occurrences from "from" to "to" (both inclusive) in order
+func (s *Set) Between(from, to time.Time) []time.Time {
+	excluded := make(map[time.Time]bool, len(s.ExDates))
+	for _, date := range s.ExDates {
+		excluded[date] = true
+	}
+
+	seen := map[time.Time]bool{}
+	var dates []time.Time
+	add := func(date time.Time) {
+		if date.Before(from) || date.After(to) || excluded[date] || seen[date] {
+			return
+		}
+		seen[date] = true
+		dates = append(dates, date)
+	}
+
+	s.each(func(date time.Time) bool {
+		if date.After(to) {
+			return false
+		}
+		add(date)
+		return true
+	})
+	for _, date := range s.RDates {
+		add(date)
+	}
+
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+	return dates
+}
+
+// Includes reports whether the date is an occurrence of the series
+func (s *Set) Includes(date time.Time) bool {
+	return len(s.Between(date, date)) == 1
+}
+
+// StartMatches reports whether the start date itself matches the rule. RFC 5545 always counts the
+// start as the first occurrence, so a start outside the pattern would add a stray occurrence.
+func (s *Set) StartMatches() bool {
+	return containsDate(s.Rule.candidates(s.Start, 0), s.Start)
+}
+
+// CountBefore returns how many dates the rule produced before the given date (EXDATEs included,
+// as they count towards COUNT). Used to keep COUNT right when a series is split.
+func (s *Set) CountBefore(date time.Time) int {
+	count := 0
+	s.each(func(d time.Time) bool {
+		if !d.Before(date) {
+			return false
+		}
+		count++
+		return true
+	})
+	return count
+}
+
+// each calls yield with every date produced by the rule (the start date first) until yield
+// returns false or the rule ends
+func (s *Set) each(yield func(time.Time) bool) {
+	rule := s.Rule
+	emitted := 0
+	emit := func(date time.Time) bool {
+		if !rule.Until.IsZero() && date.After(rule.Until) {
+			return false
+		}
+		emitted++
+		if !yield(date) {
+			return false
+		}
+		return rule.Count == 0 || emitted < rule.Count
+	}
+
+	// The start date is always the first occurrence (RFC 5545, DTSTART)
+	if !emit(s.Start) {
+		return
+	}
+
+	for period := 0; period < maxPeriods; period++ {
+		for _, date := range rule.candidates(s.Start, period) {
+			if !date.After(s.Start) {
+				continue
+			}
+			if !emit(date) {
+				return
+			}
+		}
+	}
+}
+
+// candidates returns the dates of the n-th period (day, week, month or year) of the rule in order
+func (r *Rule) candidates(start time.Time, period int) []time.Time {
+	step := period * r.Interval
+
+	switch r.Freq {
+	case Daily:
+		date := start.AddDate(0, 0, step)
+		if r.matchesMonth(date) && r.matchesMonthDay(date) && r.matchesWeekday(date) {
+			return []time.Time{date}
+		}
+		return nil
+
+	case Weekly:
+		weekStart := start.AddDate(0, 0, -mondayOffset(start.Weekday())+7*step)
+		weekdays := []time.Weekday{start.Weekday()}
+		if len(r.ByDay) > 0 {
+			weekdays = weekdays[:0]
+			for _, day := range r.ByDay {
+				weekdays = append(weekdays, day.Weekday)
+			}
+		}
+
+		var dates []time.Time
+		for _, weekday := range weekdays {
+			date := weekStart.AddDate(0, 0, mondayOffset(weekday))
+			if r.matchesMonth(date) {
+				dates = append(dates, date)
+			}
+		}
+		return sortUnique(dates)
+
+	case Monthly:
+		first := time.Date(start.Year(), start.Month()+time.Month(step), 1, 0, 0, 0, 0, time.UTC)
+		if !r.matchesMonth(first) {
+			return nil
+		}
+		return r.monthDates(first, start.Day())
+
+	case Yearly:
+		year := start.Year() + step
+
+		// Numbered weekdays without BYMONTH count within the whole year (e.g. 20MO)
+		if len(r.ByMonth) == 0 && len(r.ByDay) > 0 && len(r.ByMonthDay) == 0 {
+			first := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+			return weekdayDates(first, first.AddDate(1, 0, -1), r.ByDay)
+		}
+
+		months := r.ByMonth
+		if len(months) == 0 {
+			if len(r.ByMonthDay) > 0 {
+				months = []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}
+			} else {
+				months = []int{int(start.Month())}
+			}
+		}
+
+		var dates []time.Time
+		for _, month := range months {
+			first := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+			dates = append(dates, r.monthDates(first, start.Day())...)
+		}
+		return sortUnique(dates)
+	}
+
+	return nil
+}
+
+// monthDates returns the matching dates of the month starting at "first"
+func (r *Rule) monthDates(first time.Time, defaultDay int) []time.Time {
+	last := first.AddDate(0, 1, -1)
+
+	if len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 {
+		// Months without that day (e.g. the 31st) are skipped, as in RFC 5545
+		if defaultDay > last.Day() {
+			return nil
+		}
+		return []time.Time{first.AddDate(0, 0, defaultDay-1)}
+	}
+
+	var dates []time.Time
+	if len(r.ByMonthDay) > 0 {
+		for _, day := range r.ByMonthDay {
+			if day < 0 {
+				day = last.Day() + 1 + day
+			}
+			if day < 1 || day > last.Day() {
+				continue
+			}
+			date := first.AddDate(0, 0, day-1)
+			if len(r.ByDay) == 0 || containsDate(weekdayDates(first, last, r.ByDay), date) {
+				dates = append(dates, date)
+			}
+		}
+	} else {
+		dates = weekdayDates(first, last, r.ByDay)
+	}
+	return sortUnique(dates)
+}
+
+// weekdayDates returns the dates between first and last matching the BYDAY entries
+func weekdayDates(first, last time.Time, byDay []WeekdayNum) []time.Time {
+	var dates []time.Time
+	for _, day := range byDay {
+		var matching []time.Time
+		for date := first.AddDate(0, 0, (int(day.Weekday)-int(first.Weekday())+7)%7); !date.After(last); date = date.AddDate(0, 0, 7) {
+			matching = append(matching, date)
+		}
+
+		switch {
+		case day.N == 0:
+			dates = append(dates, matching...)
+		case day.N > 0 && day.N <= len(matching):
+			dates = append(dates, matching[day.N-1])
+		case day.N < 0 && -day.N <= len(matching):
+			dates = append(dates, matching[len(matching)+day.N])
+		}
+	}
+	return sortUnique(dates)
+}
+
+func (r *Rule) matchesMonth(date time.Time) bool {
+	if len(r.ByMonth) == 0 {
+		return true
+	}
+	for _, month := range r.ByMonth {
+		if time.Month(month) == date.Month() {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) matchesMonthDay(date time.Time) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+	lastDay := time.Date(date.Year(), date.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	for _, day := range r.ByMonthDay {
+		if day == date.Day() || (day < 0 && lastDay+1+day == date.Day()) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) matchesWeekday(date time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, day := range r.ByDay {
+		if day.Weekday == date.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+// mondayOffset returns how many days after Monday the weekday is
+func mondayOffset(weekday time.Weekday) int {
+	return (int(weekday) + 6) % 7
+}
+
+func containsDate(dates []time.Time, date time.Time) bool {
+	for _, d := range dates {
+		if d.Equal(date) {
+			return true
+		}
+	}
+	return false
+}
+
+func sortUnique(dates []time.Time) []time.Time {
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+	unique := dates[:0]
+	for i, date := range dates {
+		if i == 0 || !date.Equal(dates[i-1]) {
+			unique = append(unique, date)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,269 @@
+package recurrence
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func mustDate(t *testing.T, value string) time.Time {
+	t.Helper()
+	date, err := ParseDate(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return date
+}
+
+func mustDates(t *testing.T, values ...string) []time.Time {
+	t.Helper()
+	dates := make([]time.Time, 0, len(values))
+	for _, value := range values {
+		dates = append(dates, mustDate(t, value))
+	}
+	return dates
+}
+
+func formatDates(dates []time.Time) string {
+	values := make([]string, 0, len(dates))
+	for _, date := range dates {
+		values = append(values, FormatDate(date))
+	}
+	return strings.Join(values, ",")
+}
+
+func mustSet(t *testing.T, start, rule string) *Set {
+	t.Helper()
+	parsed, err := Parse(rule)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", rule, err)
+	}
+	return &Set{Start: mustDate(t, start), Rule: parsed}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		rule    string
+		want    string // Formatted back with String; empty when the rule is rejected
+		wantErr bool
+	}{
+		{rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE;COUNT=10", want: "FREQ=WEEKLY;INTERVAL=2;COUNT=10;BYDAY=MO,WE"},
+		{rule: "RRULE:freq=monthly;byday=-1fr", want: "FREQ=MONTHLY;BYDAY=-1FR"},
+		{rule: "FREQ=DAILY;UNTIL=20260131T235959Z", want: "FREQ=DAILY;UNTIL=20260131"},
+		{rule: "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=-1", want: "FREQ=YEARLY;BYMONTHDAY=-1;BYMONTH=2"},
+		{rule: "", wantErr: true},
+		{rule: "INTERVAL=2", wantErr: true},
+		{rule: "FREQ=HOURLY", wantErr: true},
+		{rule: "FREQ=DAILY;COUNT=3;UNTIL=20260131", wantErr: true},
+		{rule: "FREQ=DAILY;COUNT=0", wantErr: true},
+		{rule: "FREQ=DAILY;FREQ=WEEKLY", wantErr: true},
+		{rule: "FREQ=WEEKLY;BYDAY=1MO", wantErr: true},
+		{rule: "FREQ=WEEKLY;BYMONTHDAY=1", wantErr: true},
+		{rule: "FREQ=MONTHLY;BYMONTHDAY=32", wantErr: true},
+		{rule: "FREQ=MONTHLY;BYDAY=XX", wantErr: true},
+		{rule: "FREQ=WEEKLY;WKST=SU", wantErr: true},
+		{rule: "FREQ=DAILY;BYHOUR=9", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			rule, err := Parse(tt.rule)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Parse accepted %q", tt.rule)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if got := rule.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBetween(t *testing.T) {
+	tests := []struct {
+		name     string
+		start    string
+		rule     string
+		exDates  []string
+		rDates   []string
+		from, to string
+		want     string
+	}{
+		{
+			name: "daily count", start: "2026-01-01", rule: "FREQ=DAILY;COUNT=3",
+			from: "2026-01-01", to: "2026-12-31",
+			want: "2026-01-01,2026-01-02,2026-01-03",
+		},
+		{
+			name: "weekly until is inclusive", start: "2026-01-01", rule: "FREQ=WEEKLY;UNTIL=20260115",
+			from: "2026-01-01", to: "2026-12-31",
+			want: "2026-01-01,2026-01-08,2026-01-15",
+		},
+		{
+			name: "window of an endless series", start: "2026-01-01", rule: "FREQ=WEEKLY",
+			from: "2026-02-01", to: "2026-02-28",
+			want: "2026-02-05,2026-02-12,2026-02-19,2026-02-26",
+		},
+		{
+			name: "every other week on two days", start: "2026-01-05", rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE;COUNT=5",
+			from: "2026-01-01", to: "2026-12-31",
+			want: "2026-01-05,2026-01-07,2026-01-19,2026-01-21,2026-02-02",
+		},
+		{
+			name: "monthly on the 31st skips shorter months", start: "2026-01-31", rule: "FREQ=MONTHLY;COUNT=4",
+			from: "2026-01-01", to: "2026-12-31",
+			want: "2026-01-31,2026-03-31,2026-05-31,2026-07-31",
+		},
+		{
+			name: "last day of the month", start: "2026-01-31", rule: "FREQ=MONTHLY;BYMONTHDAY=-1;COUNT=4",
+			from: "2026-01-01", to: "2026-12-31",
+			want: "2026-01-31,2026-02-28,2026-03-31,2026-04-30",
+		},
+		{
+			name: "last day of February in a leap year", start: "2028-01-31", rule: "FREQ=MONTHLY;BYMONTHDAY=-1;COUNT=2",
+			from: "2028-01-01", to: "2028-12-31",
+			want: "2028-01-31,2028-02-29",
+		},
+		{
+			name: "last Friday", start: "2026-01-30", rule: "FREQ=MONTHLY;BYDAY=-1FR;COUNT=3",
+			from: "2026-01-01", to: "2026-12-31",
+			want: "2026-01-30,2026-02-27,2026-03-27",
+		},
+		{
+			name: "second Tuesday", start: "2026-01-13", rule: "FREQ=MONTHLY;BYDAY=2TU;COUNT=3",
+			from: "2026-01-01", to: "2026-12-31",
+			want: "2026-01-13,2026-02-10,2026-03-10",
+		},
+		{
+			name: "Friday the 13th", start: "2026-02-13", rule: "FREQ=MONTHLY;BYDAY=FR;BYMONTHDAY=13;COUNT=3",
+			from: "2026-01-01", to: "2027-12-31",
+			want: "2026-02-13,2026-03-13,2026-11-13",
+		},
+		{
+			name: "weekdays of a daily rule", start: "2026-01-02", rule: "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR;COUNT=4",
+			from: "2026-01-01", to: "2026-12-31",
+			want: "2026-01-02,2026-01-05,2026-01-06,2026-01-07",
+		},
+		{
+			name: "yearly on February 29th", start: "2024-02-29", rule: "FREQ=YEARLY;COUNT=3",
+			from: "2024-01-01", to: "2040-12-31",
+			want: "2024-02-29,2028-02-29,2032-02-29",
+		},
+		{
+			name: "twentieth Monday of the year", start: "2026-05-18", rule: "FREQ=YEARLY;BYDAY=20MO;COUNT=2",
+			from: "2026-01-01", to: "2027-12-31",
+			want: "2026-05-18,2027-05-17",
+		},
+		{
+			name: "excluded dates count towards COUNT, extra dates do not", start: "2026-01-01", rule: "FREQ=DAILY;COUNT=3",
+			exDates: []string{"2026-01-02"}, rDates: []string{"2026-01-10", "2026-01-03"},
+			from: "2026-01-01", to: "2026-12-31",
+			want: "2026-01-01,2026-01-03,2026-01-10",
+		},
+		{
+			name: "extra dates outside the window", start: "2026-01-01", rule: "FREQ=WEEKLY;COUNT=2",
+			rDates: []string{"2026-03-01"},
+			from:   "2026-01-01", to: "2026-02-28",
+			want: "2026-01-01,2026-01-08",
+		},
+		{
+			name: "a rule that never matches stops after maxPeriods", start: "2026-02-01", rule: "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30",
+			from: "2026-01-01", to: "9999-12-31",
+			want: "2026-02-01",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set := mustSet(t, tt.start, tt.rule)
+			set.ExDates = mustDates(t, tt.exDates...)
+			set.RDates = mustDates(t, tt.rDates...)
+
+			got := formatDates(set.Between(mustDate(t, tt.from), mustDate(t, tt.to)))
+			if got != tt.want {
+				t.Errorf("Between = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStartMatches(t *testing.T) {
+	tests := []struct {
+		start string
+		rule  string
+		want  bool
+	}{
+		{start: "2026-01-01", rule: "FREQ=DAILY", want: true},
+		{start: "2026-01-05", rule: "FREQ=WEEKLY;BYDAY=MO,WE", want: true},
+		{start: "2026-01-06", rule: "FREQ=WEEKLY;BYDAY=MO,WE", want: false},
+		{start: "2026-01-30", rule: "FREQ=MONTHLY;BYDAY=-1FR", want: true},
+		{start: "2026-01-23", rule: "FREQ=MONTHLY;BYDAY=-1FR", want: false},
+		{start: "2026-02-28", rule: "FREQ=MONTHLY;BYMONTHDAY=-1", want: true},
+		{start: "2026-02-27", rule: "FREQ=MONTHLY;BYMONTHDAY=-1", want: false},
+		{start: "2026-03-15", rule: "FREQ=YEARLY;BYMONTH=3", want: true},
+		{start: "2026-04-15", rule: "FREQ=YEARLY;BYMONTH=3", want: false},
+		{start: "2026-02-01", rule: "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.start+" "+tt.rule, func(t *testing.T) {
+			if got := mustSet(t, tt.start, tt.rule).StartMatches(); got != tt.want {
+				t.Errorf("StartMatches = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestSplit checks that a series split the way "this and following" edits do it (COUNT shared
+// with CountBefore, UNTIL ending the first part the day before) keeps exactly the same dates
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name    string
+		start   string
+		rule    string
+		exDates []string
+		split   string
+		want    int // CountBefore the split date
+	}{
+		{name: "count", start: "2026-01-01", rule: "FREQ=DAILY;COUNT=10", split: "2026-01-06", want: 5},
+		{name: "count with excluded dates", start: "2026-01-01", rule: "FREQ=DAILY;COUNT=10", exDates: []string{"2026-01-03"}, split: "2026-01-06", want: 5},
+		{name: "count on skipped month ends", start: "2026-01-31", rule: "FREQ=MONTHLY;COUNT=6", split: "2026-05-31", want: 2},
+		{name: "until", start: "2026-01-05", rule: "FREQ=WEEKLY;BYDAY=MO,TH;UNTIL=20260226", split: "2026-02-02", want: 8},
+		{name: "endless", start: "2026-01-30", rule: "FREQ=MONTHLY;BYDAY=-1FR", split: "2026-04-24", want: 3},
+	}
+
+	from, to := mustDate(t, "2026-01-01"), mustDate(t, "2027-12-31")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set := mustSet(t, tt.start, tt.rule)
+			set.ExDates = mustDates(t, tt.exDates...)
+			split := mustDate(t, tt.split)
+
+			count := set.CountBefore(split)
+			if count != tt.want {
+				t.Errorf("CountBefore = %d, want %d", count, tt.want)
+			}
+
+			beforeRule, afterRule := *set.Rule, *set.Rule
+			if set.Rule.Count > 0 {
+				beforeRule.Count = count
+				afterRule.Count = set.Rule.Count - count
+			} else {
+				beforeRule.Until = split.AddDate(0, 0, -1)
+			}
+			before := &Set{Start: set.Start, Rule: &beforeRule, ExDates: set.ExDates}
+			after := &Set{Start: split, Rule: &afterRule, ExDates: set.ExDates}
+
+			want := formatDates(set.Between(from, to))
+			got := formatDates(append(before.Between(from, to), after.Between(from, to)...))
+			if got != want {
+				t.Errorf("split series = %s, want %s", got, want)
+			}
+		})
+	}
+}
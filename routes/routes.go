@@ -3,6 +3,7 @@ package routes
 import (
 	"tools-backend/controllers"
 	"tools-backend/middleware"
+	"tools-backend/models"
 
 	"github.com/gin-gonic/gin"
 )
@@ -21,6 +22,13 @@ func SetupRoutes() *gin.Engine {
 	eventStatusController := &controllers.EventStatusController{}
 	searchController := &controllers.SearchController{}
 	userController := &controllers.UserController{}
+	twoFactorController := &controllers.TwoFactorController{}
+	oidcController := &controllers.OIDCController{}
+	apiKeyController := &controllers.APIKeyController{}
+	sessionController := &controllers.SessionController{}
+	adminController := &controllers.AdminController{}
+	invitationController := &controllers.InvitationController{}
+	inviteLinkController := &controllers.InviteLinkController{}
 
 	// API version 1
 	v1 := router.Group("/api/v1")
@@ -31,41 +39,137 @@ func SetupRoutes() *gin.Engine {
 			// Auth routes
 			public.POST("/register", authController.Register)
 			public.POST("/login", authController.Login)
+			public.POST("/login/2fa", authController.LoginTwoFactor)
+			public.POST("/token/refresh", authController.RefreshToken)
+			public.POST("/password/forgot", authController.ForgotPassword)
+			public.POST("/password/reset", authController.ResetPassword)
+			public.GET("/email/verify", authController.VerifyEmail)
+			public.POST("/email/verify/resend", authController.ResendVerification)
+			public.GET("/account/unlock", authController.UnlockAccount)
+			public.GET("/email/change/confirm", userController.ConfirmEmailChange)
+
+			// Guest invitation routes (authorized by the emailed link)
+			public.GET("/invitations/:token", invitationController.GetInvitation)
+			public.POST("/invitations/:token/rsvp", invitationController.RespondToInvitation)
+
+			// Public event pages (no account needed)
+			public.GET("/public/events", middleware.ViewerTimezone(), eventController.DiscoverEvents)
+			public.GET("/public/events/:id", middleware.ViewerTimezone(), eventController.GetPublicEvent)
+
+			// OpenID Connect login routes
+			public.GET("/oidc/providers", oidcController.ListProviders)
+			public.GET("/oidc/:provider/login", oidcController.Login)
+			public.GET("/oidc/:provider/callback", oidcController.Callback)
+			public.POST("/oidc/:provider/callback", oidcController.Callback)
 		}
 
 		// Protected routes (authentication required)
 		protected := v1.Group("/")
-		protected.Use(middleware.Auth())
+		protected.Use(middleware.Auth(), middleware.ViewerTimezone())
+
+		// Account security routes (not available to API keys or impersonation tokens)
+		account := protected.Group("/")
+		account.Use(middleware.RejectAPIKeys(), middleware.NoImpersonation())
+		{
+			// Session routes
+			account.POST("/logout", authController.Logout)
+			account.POST("/logout-all", authController.LogoutAll)
+			account.GET("/sessions", sessionController.GetSessions)
+			account.DELETE("/sessions/:id", sessionController.RevokeSession)
+
+			// Two-factor authentication routes
+			account.POST("/2fa/setup", twoFactorController.Setup)
+			account.POST("/2fa/confirm", twoFactorController.Confirm)
+			account.POST("/2fa/disable", twoFactorController.Disable)
+			account.POST("/2fa/recovery-codes", twoFactorController.RegenerateRecoveryCodes)
+
+			// API key routes
+			account.POST("/api-keys", apiKeyController.CreateAPIKey)
+			account.GET("/api-keys", apiKeyController.ListAPIKeys)
+			account.DELETE("/api-keys/:id", apiKeyController.RevokeAPIKey)
+
+			// Account management routes
+			account.PUT("/me/password", userController.ChangePassword)
+			account.PUT("/me/email", userController.ChangeEmail)
+			account.DELETE("/me", userController.DeleteAccount)
+		}
+
+		// Protected routes that unverified accounts may only read (EMAIL_VERIFICATION_POLICY=read_only).
+		// Routes marked Destructive are refused to impersonation tokens
+		verified := protected.Group("/")
+		verified.Use(middleware.RequireVerifiedEmail())
 		{
 			// Event Management routes
-			protected.POST("/events", eventController.CreateEvent)
-			protected.GET("/events/:id", eventController.GetEventByID)
-			protected.GET("/events/organized", eventController.GetOrganizedEvents)
-			protected.GET("/events/invited", eventController.GetInvitedEvents)
-			protected.PUT("/events/:id", eventController.UpdateEvent)
-			protected.DELETE("/events/:id", eventController.DeleteEvent)
-			protected.POST("/events/:id/invite", eventController.InviteToEvent)
+			verified.POST("/events", eventController.CreateEvent)
+			verified.GET("/events/:id", eventController.GetEventByID)
+			verified.GET("/events/organized", eventController.GetOrganizedEvents)
+			verified.GET("/events/invited", eventController.GetInvitedEvents)
+			verified.GET("/events/deleted", eventController.ListDeletedEvents)
+			verified.PUT("/events/:id", eventController.UpdateEvent)
+			verified.PATCH("/events/:id", eventController.PatchEvent)
+			verified.DELETE("/events/:id", middleware.Destructive(), eventController.DeleteEvent)
+			verified.POST("/events/:id/cancel", middleware.Destructive(), eventController.CancelEvent)
+			verified.POST("/events/:id/restore", eventController.RestoreEvent)
+			verified.GET("/events/:id/history", eventController.GetEventHistory)
+			verified.POST("/events/:id/history/:version/revert", middleware.Destructive(), eventController.RevertEvent)
+			verified.POST("/events/:id/invite", eventController.InviteToEvent)
+			verified.PUT("/events/:id/participants/:userId/role", eventController.UpdateParticipantRole)
+			verified.POST("/events/:id/transfer-ownership", middleware.Destructive(), eventController.TransferOwnership)
+			verified.DELETE("/events/:id/participants/:userId", middleware.Destructive(), eventController.RemoveParticipant)
+			verified.POST("/events/:id/leave", middleware.Destructive(), eventController.LeaveEvent)
+			verified.POST("/events/:id/join", eventController.JoinEvent)
+			verified.GET("/events/:id/invitations", invitationController.ListEventInvitations)
+			verified.DELETE("/events/:id/invitations/:invitationId", middleware.Destructive(), invitationController.DeleteInvitation)
+			verified.POST("/events/:id/invite-links", inviteLinkController.CreateInviteLink)
+			verified.GET("/events/:id/invite-links", inviteLinkController.ListInviteLinks)
+			verified.DELETE("/events/:id/invite-links/:linkId", middleware.Destructive(), inviteLinkController.RevokeInviteLink)
+			verified.POST("/invite-links/:token/redeem", inviteLinkController.RedeemInviteLink)
 
 			// Event Status Management routes
-			protected.POST("/events/:id/status", eventStatusController.CreateOrUpdateEventStatus)
-			protected.GET("/events/:id/status", eventStatusController.GetUserEventStatus)
-			protected.GET("/events/:id/attendees", eventStatusController.GetEventAttendees)
-			protected.GET("/events/:id/attendees/status", eventStatusController.GetAttendeesByStatus)
+			verified.POST("/events/:id/status", eventStatusController.CreateOrUpdateEventStatus)
+			verified.GET("/events/:id/status", eventStatusController.GetUserEventStatus)
+			verified.GET("/events/:id/attendees", eventStatusController.GetEventAttendees)
+			verified.GET("/events/:id/attendees/status", eventStatusController.GetAttendeesByStatus)
 
 			// Search and Filtering routes
-			protected.POST("/search", searchController.SearchEvents)
-			protected.GET("/search/advanced", searchController.AdvancedSearch)
-			protected.POST("/search/advanced", searchController.AdvancedSearch)
-			protected.GET("/search/keyword", searchController.FilterEventsByKeyword)
-			protected.GET("/search/date", searchController.FilterEventsByDate)
-			protected.GET("/search/role", searchController.FilterEventsByRole)
-			protected.GET("/all-events", searchController.GetAllUserEvents)
+			verified.POST("/search", searchController.SearchEvents)
+			verified.GET("/search/advanced", searchController.AdvancedSearch)
+			verified.POST("/search/advanced", searchController.AdvancedSearch)
+			verified.GET("/search/keyword", searchController.FilterEventsByKeyword)
+			verified.GET("/search/date", searchController.FilterEventsByDate)
+			verified.GET("/search/role", searchController.FilterEventsByRole)
+			verified.GET("/all-events", searchController.GetAllUserEvents)
 
 			// User routes
-			protected.GET("/users/search", userController.SearchUsers)
+			verified.GET("/users/search", userController.SearchUsers)
+			verified.GET("/me", userController.GetProfile)
+			verified.PUT("/me", userController.UpdateProfile)
+		}
+
+		// Platform staff routes (moderators and admins can also use the event routes above on any event)
+		staff := protected.Group("/admin")
+		staff.Use(middleware.NoImpersonation(), middleware.RequireRole(models.PlatformRoleModerator, models.PlatformRoleAdmin))
+		{
+			staff.GET("/events", adminController.ListEvents)
+		}
+
+		admin := protected.Group("/admin")
+		admin.Use(middleware.RejectAPIKeys(), middleware.NoImpersonation(), middleware.RequireRole(models.PlatformRoleAdmin))
+		{
+			admin.GET("/users", adminController.ListUsers)
+			admin.GET("/users/:id", adminController.GetUser)
+			admin.PUT("/users/:id/role", adminController.UpdateUserRole)
+			admin.POST("/users/:id/suspend", adminController.SuspendUser)
+			admin.POST("/users/:id/reactivate", adminController.ReactivateUser)
+			admin.POST("/users/:id/force-password-reset", adminController.ForcePasswordReset)
+			admin.POST("/users/:id/merge", adminController.MergeUsers)
+			admin.POST("/users/:id/impersonate", adminController.ImpersonateUser)
 		}
 	}
 
+	// Public signing keys (JSON Web Key Set)
+	router.GET("/.well-known/jwks.json", authController.JWKS)
+
 	// Health check endpoint
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{
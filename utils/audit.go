@@ -0,0 +1,20 @@
+package utils
+
+import (
+	"context"
+	"log"
+	"time"
+	"tools-backend/database"
+	"tools-backend/models"
+)
+
+// RecordAudit stores an audit log entry; failures are logged but never block the request
+func RecordAudit(entry models.AuditLog) {
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+
+	if _, err := database.GetCollection("audit_logs").InsertOne(context.TODO(), entry); err != nil {
+		log.Printf("Failed to record audit entry %s: %v", entry.Action, err)
+	}
+}
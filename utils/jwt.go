@@ -1,21 +1,145 @@
 package utils
 
 import (
+	"errors"
+	"fmt"
 	"time"
 	"tools-backend/config"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-// GenerateJWT generates a JWT token (similar to Laravel's JWT token generation)
-func GenerateJWT(userID, email string) (string, error) {
-	claims := jwt.MapClaims{
-		"user_id": userID,
-		"email":   email,
-		"exp":     time.Now().Add(time.Hour * 24).Unix(), // 24 hours
-		"iat":     time.Now().Unix(),
+// AccessToken holds a signed access token together with its identifiers
+type AccessToken struct {
+	Token     string
+	JTI       string
+	ExpiresAt time.Time
+}
+
+// GenerateAccessToken generates a short-lived access token (similar to Laravel's JWT token generation)
+func GenerateAccessToken(userID, email string, extra jwt.MapClaims) (*AccessToken, error) {
+	return GenerateAccessTokenWithTTL(userID, email, extra, config.GetAccessTokenTTL())
+}
+
+// GenerateAccessTokenWithTTL generates an access token with a custom lifetime (e.g. impersonation)
+func GenerateAccessTokenWithTTL(userID, email string, extra jwt.MapClaims, ttl time.Duration) (*AccessToken, error) {
+	jti, err := GenerateRandomToken(16)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+	claims := jwt.MapClaims{}
+	for key, value := range extra {
+		claims[key] = value
+	}
+	claims["user_id"] = userID
+	claims["email"] = email
+	claims["jti"] = jti
+	claims["typ"] = "access"
+	claims["exp"] = expiresAt.Unix()
+	claims["iat"] = now.Unix()
+
+	signed, err := signClaims(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AccessToken{Token: signed, JTI: jti, ExpiresAt: expiresAt}, nil
+}
+
+// GenerateSignedToken creates a signed, expiring token that is only valid for one purpose
+func GenerateSignedToken(purpose string, claims jwt.MapClaims, ttl time.Duration) (string, error) {
+	now := time.Now()
+	all := jwt.MapClaims{}
+	for key, value := range claims {
+		all[key] = value
+	}
+	all["typ"] = purpose
+	all["iat"] = now.Unix()
+	all["exp"] = now.Add(ttl).Unix()
+
+	return signClaims(all)
+}
+
+// ParseSignedToken parses a token and checks that it was issued for the given purpose
+func ParseSignedToken(tokenString, purpose string) (jwt.MapClaims, error) {
+	claims, err := ParseJWT(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims["typ"] != purpose {
+		return nil, errors.New("token was not issued for this purpose")
 	}
+	return claims, nil
+}
+
+// ParseJWT parses and validates a token, returning its claims.
+// Only the algorithms of the loaded key set are accepted and the key is picked by "kid".
+func ParseJWT(tokenString string) (jwt.MapClaims, error) {
+	parser := jwt.NewParser(
+		jwt.WithValidMethods(allowedMethods()),
+		jwt.WithIssuer(config.GetJWTIssuer()),
+		jwt.WithExpirationRequired(),
+	)
+
+	token, err := parser.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if keySet.active == nil {
+			return []byte(config.GetJWTSecret()), nil
+		}
+
+		kid, _ := token.Header["kid"].(string)
+		key, ok := keySet.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown key id %q", kid)
+		}
+		if token.Method.Alg() != key.Method.Alg() {
+			return nil, fmt.Errorf("algorithm %s does not match key %q", token.Method.Alg(), kid)
+		}
+		return key.Public, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, errors.New("invalid token claims")
+	}
+	return claims, nil
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(config.GetJWTSecret()))
+// signClaims signs the claims with the active key (or the HS256 secret when no key set is loaded)
+func signClaims(claims jwt.MapClaims) (string, error) {
+	claims["iss"] = config.GetJWTIssuer()
+
+	if keySet.active == nil {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		return token.SignedString([]byte(config.GetJWTSecret()))
+	}
+
+	token := jwt.NewWithClaims(keySet.active.Method, claims)
+	token.Header["kid"] = keySet.active.ID
+	return token.SignedString(keySet.active.Private)
+}
+
+// allowedMethods lists the algorithms a token may be signed with
+func allowedMethods() []string {
+	if keySet.active == nil {
+		return []string{jwt.SigningMethodHS256.Alg()}
+	}
+
+	seen := make(map[string]bool)
+	var methods []string
+	for _, key := range keySet.keys {
+		if alg := key.Method.Alg(); !seen[alg] {
+			seen[alg] = true
+			methods = append(methods, alg)
+		}
+	}
+	return methods
 }
@@ -0,0 +1,173 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"tools-backend/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// SigningKey is one key of the JWT key set
+type SigningKey struct {
+	ID      string
+	Method  jwt.SigningMethod
+	Private crypto.Signer // nil for verify-only (retired) keys
+	Public  crypto.PublicKey
+}
+
+// keySet holds every key that may verify tokens and the one that signs new tokens;
+// it stays nil when JWT_KEYS_DIR is not set and tokens fall back to HS256
+var keySet struct {
+	keys   map[string]*SigningKey
+	active *SigningKey
+}
+
+// LoadSigningKeys loads the JWT key set from JWT_KEYS_DIR.
+// Every <kid>.pem file holds an RSA or Ed25519 key: private keys can sign and verify,
+// public keys only verify (for tokens signed by a key that is being retired).
+// JWT_ACTIVE_KID picks the signing key, otherwise the last private key by name is used.
+func LoadSigningKeys() error {
+	dir := config.GetJWTKeysDir()
+	if dir == "" {
+		if config.GetJWTSecret() == "your-secret-key" {
+			log.Println("WARNING: JWT_KEYS_DIR is not set and JWT_SECRET uses the default value")
+		}
+		return nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return err
+	}
+
+	keys := make(map[string]*SigningKey)
+	var signingIDs []string
+	for _, file := range files {
+		kid := strings.TrimSuffix(filepath.Base(file), ".pem")
+		key, err := loadKeyFile(kid, file)
+		if err != nil {
+			return fmt.Errorf("loading key %s: %w", file, err)
+		}
+		keys[kid] = key
+		if key.Private != nil {
+			signingIDs = append(signingIDs, kid)
+		}
+	}
+
+	if len(signingIDs) == 0 {
+		return fmt.Errorf("no private key found in %s", dir)
+	}
+
+	activeID := config.GetEnv("JWT_ACTIVE_KID", "")
+	if activeID == "" {
+		sort.Strings(signingIDs)
+		activeID = signingIDs[len(signingIDs)-1]
+	}
+
+	active, ok := keys[activeID]
+	if !ok || active.Private == nil {
+		return fmt.Errorf("active key %q is not a private key in %s", activeID, dir)
+	}
+
+	keySet.keys = keys
+	keySet.active = active
+	log.Printf("Loaded %d JWT key(s), signing with %q (%s)", len(keys), active.ID, active.Method.Alg())
+	return nil
+}
+
+// loadKeyFile parses a PEM encoded RSA or Ed25519 key
+func loadKeyFile(kid, path string) (*SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var parsed interface{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key := &SigningKey{ID: kid}
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.Method, key.Private, key.Public = jwt.SigningMethodRS256, k, &k.PublicKey
+	case *rsa.PublicKey:
+		key.Method, key.Public = jwt.SigningMethodRS256, k
+	case ed25519.PrivateKey:
+		key.Method, key.Private, key.Public = jwt.SigningMethodEdDSA, k, k.Public()
+	case ed25519.PublicKey:
+		key.Method, key.Public = jwt.SigningMethodEdDSA, k
+	default:
+		return nil, fmt.Errorf("unsupported key type %T (use RSA or Ed25519)", parsed)
+	}
+
+	if rsaKey, ok := key.Public.(*rsa.PublicKey); ok && rsaKey.N.BitLen() < 2048 {
+		return nil, errors.New("RSA keys must be at least 2048 bits")
+	}
+	return key, nil
+}
+
+// JWKS returns the public keys as a JSON Web Key Set (RFC 7517)
+func JWKS() gin.H {
+	keys := []gin.H{}
+	ids := make([]string, 0, len(keySet.keys))
+	for kid := range keySet.keys {
+		ids = append(ids, kid)
+	}
+	sort.Strings(ids)
+
+	for _, kid := range ids {
+		key := keySet.keys[kid]
+		switch pub := key.Public.(type) {
+		case *rsa.PublicKey:
+			keys = append(keys, gin.H{
+				"kty": "RSA",
+				"use": "sig",
+				"alg": key.Method.Alg(),
+				"kid": kid,
+				"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			keys = append(keys, gin.H{
+				"kty": "OKP",
+				"crv": "Ed25519",
+				"use": "sig",
+				"alg": key.Method.Alg(),
+				"kid": kid,
+				"x":   base64.RawURLEncoding.EncodeToString(pub),
+			})
+		}
+	}
+
+	return gin.H{"keys": keys}
+}
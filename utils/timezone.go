@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	// Embed the IANA timezone database so timezones work on hosts without zoneinfo
+	_ "time/tzdata"
+)
+
+var timezoneCache sync.Map
+
+// LoadTimezone loads an IANA timezone such as "Europe/Paris". The server's own "Local"
+// zone and the empty name are rejected so stored events never depend on the host.
+func LoadTimezone(name string) (*time.Location, error) {
+	if name == "" || name == "Local" {
+		return nil, errors.New("unknown timezone")
+	}
+	if loc, ok := timezoneCache.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, errors.New("unknown timezone")
+	}
+	timezoneCache.Store(name, loc)
+	return loc, nil
+}
@@ -24,6 +24,37 @@ func ValidateStruct(s interface{}) map[string]string {
 	return errors
 }
 
+// ValidateStructPartial validates only the given fields of a struct, named as in its JSON
+// (for partial updates, where absent fields must not fail their rules)
+func ValidateStructPartial(s interface{}, jsonFields []string) map[string]string {
+	validate := validator.New()
+	errors := make(map[string]string)
+
+	structType := reflect.TypeOf(s)
+	names := []string{}
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		for _, name := range jsonFields {
+			if GetFieldName(field) == name {
+				names = append(names, field.Name)
+			}
+		}
+	}
+	if len(names) == 0 {
+		return errors
+	}
+
+	err := validate.StructPartial(s, names...)
+	if err != nil {
+		for _, err := range err.(validator.ValidationErrors) {
+			field := strings.ToLower(err.Field())
+			errors[field] = getErrorMessage(err)
+		}
+	}
+
+	return errors
+}
+
 // getErrorMessage returns a user-friendly error message
 func getErrorMessage(fe validator.FieldError) string {
 	switch fe.Tag() {